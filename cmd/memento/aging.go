@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// lastSeen is the latest time the card's command showed up in any history.
+func lastSeen(c Card) time.Time {
+	var t time.Time
+	for _, o := range c.Origins {
+		if o.LastSeen.After(t) {
+			t = o.LastSeen
+		}
+	}
+	return t
+}
+
+func runAging(args []string) error {
+	fs := flag.NewFlagSet("aging", flag.ExitOnError)
+	backlog := fs.Int("backlog-days", 14, "list due cards left unreviewed for at least this many days")
+	stale := fs.String("stale", "6mo", "list cards whose command hasn't been run for this long (30d, 6mo, 1y)")
+	limit := fs.Int("limit", 20, "rows per section (0 = all)")
+	_ = fs.Parse(args)
+
+	now := time.Now()
+	staleBefore, err := parseAge(*stale, now)
+	if err != nil {
+		return fmt.Errorf("--stale: %w", err)
+	}
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	overdueBefore := now.AddDate(0, 0, -*backlog)
+	var late, old []Card
+	for _, c := range cards {
+		if isDue(c, now) && c.NextDue.Before(overdueBefore) {
+			late = append(late, c)
+		}
+		if t := lastSeen(c); !t.IsZero() && t.Before(staleBefore) && !c.Suspended {
+			old = append(old, c)
+		}
+	}
+	sort.SliceStable(late, func(i, j int) bool { return late[i].NextDue.Before(late[j].NextDue) })
+	sort.SliceStable(old, func(i, j int) bool { return lastSeen(old[i]).Before(lastSeen(old[j])) })
+
+	show := func(cs []Card, suffix func(Card) string) {
+		for i, c := range cs {
+			if *limit > 0 && i == *limit {
+				fmt.Printf("  … and %d more\n", len(cs)-i)
+				break
+			}
+			fmt.Printf("  %s%s\n", cardRow(c, now), suffix(c))
+		}
+	}
+	fmt.Printf("Backlog: %d cards due for %d+ days\n", len(late), *backlog)
+	show(late, func(Card) string { return "" })
+	if len(late) > 0 {
+		fmt.Printf("  → catch up with memento review, or park them: memento bulk --query 'due:<-%dd' --suspend\n", *backlog)
+	}
+	fmt.Printf("\nStale: %d cards whose command was last run before %s\n", len(old), staleBefore.Local().Format("2006-01-02"))
+	show(old, func(c Card) string { return "  (last run " + humanTime(lastSeen(c), now) + ")" })
+	if len(old) > 0 {
+		fmt.Println("  → archive with memento bulk --query 'id:<prefix>' --suspend, or drop with memento delete 'id:<prefix>'")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// accepted is every answer a card takes: its own, the alternatives, the
+// other spelling of each flag among them (-R / --recursive), and flag+value
+// answers with or without their placeholder.
+func accepted(c Card) []string {
+	out := append([]string{c.Answer}, c.AltAnswers...)
+	words := strings.Fields(c.Command)
+	for _, a := range out[:len(out):len(out)] {
+		f, ph, ok := flagValue(a)
+		if ok {
+			out = append(out, f)
+		}
+		for _, e := range flagEquivalents(words, f) {
+			out = append(out, e)
+			if ok {
+				out = append(out, e+" "+ph)
+			}
+		}
+	}
+	return out
+}
+
+// parseAlts splits a comma-separated list, dropping blanks, repeats and the
+// main answer.
+func parseAlts(s, answer string) []string {
+	out := []string{}
+	seen := map[string]bool{strings.ToLower(answer): true}
+	for _, a := range strings.Split(s, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" || seen[strings.ToLower(a)] {
+			continue
+		}
+		seen[strings.ToLower(a)] = true
+		out = append(out, a)
+	}
+	return out
+}
+
+// startAlt edits the current card's alternative answers, offering what was
+// just typed when it was marked wrong.
+func (m *model) startAlt() {
+	c := m.cards[m.idx]
+	alts := c.AltAnswers
+	if ans := m.answer(); ans != "" && !checkAnswer(c, ans) {
+		alts = append(alts[:len(alts):len(alts)], ans)
+	}
+	m.altIn = textinput.New()
+	m.altIn.Prompt = "also accept: "
+	m.altIn.Placeholder = "comma-separated, e.g. --namespace"
+	m.altIn.SetValue(strings.Join(alts, ", "))
+	m.altIn.CursorEnd()
+	m.altIn.Focus()
+	m.editingAlt = true
+}
+
+func (m model) updateAlt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.editingAlt = false
+		return m, nil
+	case "enter":
+		c := &m.cards[m.idx]
+		c.AltAnswers = parseAlts(m.altIn.Value(), c.Answer)
+		for i := range m.cards { // the relearn copy too
+			if m.cards[i].ID == c.ID {
+				m.cards[i].AltAnswers = c.AltAnswers
+			}
+		}
+		m.editingAlt = false
+		if err := saveAlts(*c); err != nil {
+			m.flash = "not saved: " + err.Error()
+			return m, nil
+		}
+		m.flash = "accepted answers: " + strings.Join(accepted(*c), ", ") + " (from the next review)"
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.altIn, cmd = m.altIn.Update(msg)
+	return m, cmd
+}
+
+// saveAlts stores only the alternative answers.
+func saveAlts(c Card) error {
+	return updateCards(func(cards []Card) []Card {
+		if i, err := findCard(cards, c.ID); err == nil {
+			cards[i].AltAnswers = c.AltAnswers
+		}
+		return cards
+	})
+}
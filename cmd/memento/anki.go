@@ -0,0 +1,181 @@
+package main
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+	"memento/pkg/srs"
+
+	_ "modernc.org/sqlite"
+)
+
+// Anki .apkg import: a zip holding a SQLite collection (collection.anki21 or .anki2).
+
+var (
+	htmlTag = regexp.MustCompile(`(?i)<br\s*/?>|<div>|</div>|<[^>]+>`)
+	clozeRe = regexp.MustCompile(`\{\{c(\d+)::(.*?)(?:::(.*?))?\}\}`)
+)
+
+const ankiClozeModel = 1
+
+type ankiModelInfo struct {
+	Type int `json:"type"`
+}
+
+func stripHTML(s string) string {
+	s = htmlTag.ReplaceAllStringFunc(s, func(t string) string {
+		if strings.HasPrefix(strings.ToLower(t), "<br") || strings.EqualFold(t, "<div>") {
+			return " "
+		}
+		return ""
+	})
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(wsCollapse.ReplaceAllString(s, " "))
+}
+
+func extractCollection(apkg string) (path string, cleanup func(), err error) {
+	zr, err := zip.OpenReader(apkg)
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+	var f *zip.File
+	for _, name := range []string{"collection.anki21", "collection.anki2"} {
+		for _, zf := range zr.File {
+			if zf.Name == name {
+				f = zf
+				break
+			}
+		}
+		if f != nil {
+			break
+		}
+	}
+	if f == nil {
+		for _, zf := range zr.File {
+			if zf.Name == "collection.anki21b" {
+				return "", nil, fmt.Errorf("%s: compressed anki21b collections are not supported; export with \"Support older Anki versions\"", apkg)
+			}
+		}
+		return "", nil, fmt.Errorf("%s: no Anki collection found", apkg)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return "", nil, err
+	}
+	defer rc.Close()
+	tmp, err := os.CreateTemp("", "memento-anki-*.sqlite")
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := io.Copy(tmp, rc); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", nil, err
+	}
+	tmp.Close()
+	return tmp.Name(), func() { os.Remove(tmp.Name()) }, nil
+}
+
+func ImportAnki(apkg string, now time.Time) ([]Card, error) {
+	dbPath, cleanup, err := extractCollection(apkg)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(dbPath)+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var crt int64
+	var modelsJSON string
+	if err := db.QueryRow(`SELECT crt, models FROM col`).Scan(&crt, &modelsJSON); err != nil {
+		return nil, fmt.Errorf("read anki collection: %w", err)
+	}
+	models := map[string]ankiModelInfo{}
+	if err := json.Unmarshal([]byte(modelsJSON), &models); err != nil {
+		return nil, fmt.Errorf("read anki note types: %w", err)
+	}
+	created := time.Unix(crt, 0)
+
+	rows, err := db.Query(`SELECT n.id, n.mid, n.flds, n.tags, c.ord, c.type, c.due, c.ivl, c.reps
+		FROM cards c JOIN notes n ON n.id = c.nid ORDER BY n.id, c.ord`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Card{}
+	for rows.Next() {
+		var nid, mid, due int64
+		var flds, tags string
+		var ord, ctype, ivl, reps int
+		if err := rows.Scan(&nid, &mid, &flds, &tags, &ord, &ctype, &due, &ivl, &reps); err != nil {
+			return nil, err
+		}
+		fields := strings.Split(flds, "\x1f")
+		var c Card
+		if models[strconv.FormatInt(mid, 10)].Type == ankiClozeModel {
+			c = ankiClozeCard(fields[0], ord+1)
+		} else {
+			if ord != 0 || len(fields) < 2 {
+				continue // reverse templates etc. have no sensible mapping
+			}
+			c = Card{Type: cards.FullRecall, Prompt: stripHTML(fields[0]), Answer: stripHTML(fields[1]), Hint: "Recall the answer"}
+			c.Command = c.Answer
+		}
+		if c.Prompt == "" || c.Answer == "" {
+			continue
+		}
+		c.ID = hash(fmt.Sprintf("anki:%d:%d", nid, ord))
+		c.Tags = unique(append(strings.Fields(tags), "anki"))
+		c.TimesSeen = reps
+		c.SeenCount = 1
+
+		// Anki card types: 0 new, 1 learning, 2 review, 3 relearning.
+		switch ctype {
+		case 2:
+			c.Box = srs.BoxForInterval(ivl)
+			c.NextDue = created.AddDate(0, 0, int(due))
+		case 1, 3:
+			c.Box = 1
+			c.NextDue = time.Unix(due, 0)
+		default:
+			c.Box = 1
+			c.NextDue = now
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func ankiClozeCard(text string, n int) Card {
+	text = stripHTML(text)
+	var answer, hint string
+	prompt := clozeRe.ReplaceAllStringFunc(text, func(m string) string {
+		sm := clozeRe.FindStringSubmatch(m)
+		if sm[1] == strconv.Itoa(n) { // repeated: one blank each, scored per blank
+			answer, hint = strings.TrimSpace(answer+" "+sm[2]), sm[3]
+			return "_____"
+		}
+		return sm[2]
+	})
+	full := clozeRe.ReplaceAllString(text, "$2")
+	if hint == "" {
+		hint = "Type the missing flag/subcommand"
+	}
+	return Card{Type: cards.Cloze, Prompt: prompt, Answer: answer, Hint: hint, Command: full}
+}
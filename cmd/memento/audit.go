@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"memento/pkg/ingest"
+)
+
+// Scrub audit: shows *scrubbed* lines only — the report never echoes a secret.
+
+type auditEntry struct {
+	Scrubbed string
+	Rules    []string
+	Files    []string
+	Count    int
+}
+
+// AuditHistory lists every distinct history line that had material redacted.
+func AuditHistory(srcs []ingest.Source) []auditEntry {
+	byLine := map[string]*auditEntry{}
+	ingest.Walk(srcs, func(raw string, _ time.Time, origin Origin) {
+		scrubbed, rules := scrubReport(raw)
+		if len(rules) == 0 {
+			return
+		}
+		e, ok := byLine[scrubbed]
+		if !ok {
+			e = &auditEntry{Scrubbed: scrubbed}
+			byLine[scrubbed] = e
+		}
+		e.Count++
+		e.Rules = unique(append(e.Rules, rules...))
+		e.Files = unique(append(e.Files, origin.File))
+	})
+	out := make([]auditEntry, 0, len(byLine))
+	for _, e := range byLine {
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Scrubbed < out[j].Scrubbed })
+	return out
+}
+
+// AuditCards finds stored cards whose text would still trip a scrub rule.
+func AuditCards(cards []Card) map[string][]string {
+	leaks := map[string][]string{}
+	for _, c := range cards {
+		for _, field := range []string{c.Command, c.Prompt, c.Answer, c.Hint, c.Notes} {
+			if _, rules := scrubReport(field); len(rules) > 0 {
+				leaks[c.ID] = unique(append(leaks[c.ID], rules...))
+			}
+		}
+	}
+	return leaks
+}
+
+func printAudit(w io.Writer, entries []auditEntry, leaks map[string][]string) {
+	fmt.Fprintf(w, "%d history lines had material redacted:\n", len(entries))
+	ruleCounts := map[string]int{}
+	for _, e := range entries {
+		fmt.Fprintf(w, "  [%s] ×%d  %s\n      in %s\n", strings.Join(e.Rules, ","), e.Count, e.Scrubbed, strings.Join(e.Files, ", "))
+		for _, r := range e.Rules {
+			ruleCounts[r] += e.Count
+		}
+	}
+	if len(ruleCounts) > 0 {
+		rules := make([]string, 0, len(ruleCounts))
+		for r := range ruleCounts {
+			rules = append(rules, r)
+		}
+		sort.Strings(rules)
+		fmt.Fprintln(w, "By rule:")
+		for _, r := range rules {
+			fmt.Fprintf(w, "  %-24s %d\n", r, ruleCounts[r])
+		}
+	}
+	if leaks == nil {
+		return
+	}
+	if len(leaks) == 0 {
+		fmt.Fprintln(w, "cards.json: clean — no stored card matches a scrub rule.")
+		return
+	}
+	fmt.Fprintf(w, "cards.json: %d card(s) still match scrub rules — review before syncing:\n", len(leaks))
+	ids := make([]string, 0, len(leaks))
+	for id := range leaks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(w, "  %s  [%s]\n", shortID(id), strings.Join(leaks[id], ","))
+	}
+}
+
+func runAudit(args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	_ = fs.Parse(args)
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	printAudit(os.Stdout, AuditHistory(defaultSources()), AuditCards(cards))
+	return nil
+}
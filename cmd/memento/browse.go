@@ -0,0 +1,247 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type browseKeys struct {
+	Up, Down, PageUp, PageDown, Sort, Reverse, Detail, Pin, Merge, Quit key.Binding
+}
+
+func defaultBrowseKeys() browseKeys {
+	return browseKeys{
+		Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+		Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup", "b"), key.WithHelp("pgup", "page up")),
+		PageDown: key.NewBinding(key.WithKeys("pgdown", " "), key.WithHelp("pgdn", "page down")),
+		Sort:     key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "next sort")),
+		Reverse:  key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "reverse")),
+		Detail:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "details")),
+		Pin:      key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pin/unpin")),
+		Merge:    key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "mark/merge")),
+		Quit:     key.NewBinding(key.WithKeys("q", "esc", "ctrl+c"), key.WithHelp("q", "quit")),
+	}
+}
+
+// browser is a scrollable card list with sort toggling (memento browse).
+type browser struct {
+	cards   []Card
+	cursor  int
+	offset  int
+	height  int
+	sortIdx int
+	reverse bool
+	detail  bool
+	query   string
+	keys    browseKeys
+	th      theme
+	mark    string // ID of the card m merges others into
+	err     error  // last failed save
+}
+
+func newBrowser(cards []Card, by string, reverse bool, query string, th theme) browser {
+	b := browser{cards: cards, height: 20, reverse: reverse, query: query, keys: defaultBrowseKeys(), th: th}
+	for i, s := range cardSorts {
+		if s == by {
+			b.sortIdx = i
+		}
+	}
+	b.resort()
+	return b
+}
+
+func (b *browser) resort() {
+	var id string
+	if len(b.cards) > 0 {
+		id = b.cards[b.cursor].ID
+	}
+	_ = sortCards(b.cards, cardSorts[b.sortIdx], b.reverse)
+	for i, c := range b.cards { // keep the cursor on the same card
+		if c.ID == id {
+			b.cursor = i
+		}
+	}
+	b.scroll()
+}
+
+func (b *browser) scroll() {
+	rows := b.rows()
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+rows {
+		b.offset = b.cursor - rows + 1
+	}
+}
+
+// rows is how many card rows fit next to the header, footer and detail pane.
+func (b browser) rows() int {
+	n := b.height - 4
+	if b.detail {
+		n -= 9
+	}
+	return max(n, 3)
+}
+
+func (b browser) Init() tea.Cmd { return nil }
+
+func (b browser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, b.keys.Quit):
+			return b, tea.Quit
+		case key.Matches(msg, b.keys.Up):
+			b.cursor = max(b.cursor-1, 0)
+		case key.Matches(msg, b.keys.Down):
+			b.cursor = min(b.cursor+1, max(len(b.cards)-1, 0))
+		case key.Matches(msg, b.keys.PageUp):
+			b.cursor = max(b.cursor-b.rows(), 0)
+		case key.Matches(msg, b.keys.PageDown):
+			b.cursor = min(b.cursor+b.rows(), max(len(b.cards)-1, 0))
+		case key.Matches(msg, b.keys.Sort):
+			b.sortIdx = (b.sortIdx + 1) % len(cardSorts)
+			b.resort()
+		case key.Matches(msg, b.keys.Reverse):
+			b.reverse = !b.reverse
+			b.resort()
+		case key.Matches(msg, b.keys.Detail):
+			b.detail = !b.detail
+		case key.Matches(msg, b.keys.Pin) && len(b.cards) > 0:
+			c := &b.cards[b.cursor]
+			c.Pinned = !c.Pinned
+			if b.err = SaveProgress(*c); b.err != nil {
+				c.Pinned = !c.Pinned
+			}
+		case key.Matches(msg, b.keys.Merge) && len(b.cards) > 0:
+			b.merge()
+		}
+	}
+	b.scroll()
+	return b, nil
+}
+
+// merge marks the card under the cursor, or merges it into the marked one.
+func (b *browser) merge() {
+	id := b.cards[b.cursor].ID
+	switch b.mark {
+	case id:
+		b.mark = ""
+		return
+	case "":
+		b.mark = id
+		return
+	}
+	k, err := mergeCards(b.mark, id)
+	if b.err = err; err != nil {
+		return
+	}
+	b.mark = ""
+	b.cards = slices.Delete(b.cards, b.cursor, b.cursor+1)
+	for i := range b.cards {
+		if b.cards[i].ID == k.ID {
+			b.cards[i] = k
+			b.cursor = i
+		}
+	}
+}
+
+func (b browser) View() string {
+	now := time.Now()
+	dir := "↑"
+	if b.reverse {
+		dir = "↓"
+	}
+	title := fmt.Sprintf("memento browse — %d cards · sort: %s %s", len(b.cards), cardSorts[b.sortIdx], dir)
+	if b.query != "" {
+		title += " · " + b.query
+	}
+	var sb strings.Builder
+	sb.WriteString(b.th.Header.Render(title) + "\n\n")
+	if len(b.cards) == 0 {
+		sb.WriteString("No cards match.\n")
+	}
+	sel := lipgloss.NewStyle().Reverse(true)
+	for i := b.offset; i < len(b.cards) && i < b.offset+b.rows(); i++ {
+		row := cardRow(b.cards[i], now)
+		if b.cards[i].ID == b.mark {
+			row = "* " + row
+		}
+		if i == b.cursor {
+			row = sel.Render(row)
+		}
+		sb.WriteString(row + "\n")
+	}
+	if b.detail && len(b.cards) > 0 {
+		c := b.cards[b.cursor]
+		sb.WriteString("\n" + b.th.Prompt.Render(c.Prompt) + "\n")
+		fmt.Fprintf(&sb, "answer:  %s\n", c.Answer)
+		if len(c.AltAnswers) > 0 {
+			fmt.Fprintf(&sb, "also:    %s\n", strings.Join(c.AltAnswers, ", "))
+		}
+		if c.AnswerPattern != "" {
+			fmt.Fprintf(&sb, "pattern: %s\n", c.AnswerPattern)
+		}
+		fmt.Fprintf(&sb, "hint:    %s\ncommand: %s\ntags:    %s\n", c.Hint, c.Command, strings.Join(c.Tags, ", "))
+		fmt.Fprintf(&sb, "%s, %s, seen %d× in history\n", dueLabel(c, now), reviewedLabel(c, now), c.SeenCount)
+		if c.Example != "" {
+			fmt.Fprintf(&sb, "example: %s\n", c.Example)
+		}
+		if c.Notes != "" {
+			sb.WriteString(b.th.Faint.Render(firstLine(c.Notes)) + "\n")
+		}
+	}
+	k := b.keys
+	help := []key.Binding{k.Up, k.Down, k.Sort, k.Reverse, k.Detail, k.Pin, k.Merge, k.Quit}
+	parts := []string{}
+	for _, h := range help {
+		parts = append(parts, h.Help().Key+" "+h.Help().Desc)
+	}
+	if b.mark != "" {
+		sb.WriteString("\n" + b.th.Faint.Render("* marked "+shortID(b.mark)+": m on another card merges it in, m here unmarks"))
+	}
+	if b.err != nil {
+		sb.WriteString("\n" + b.th.Wrong.Render(b.err.Error()))
+	}
+	sb.WriteString("\n" + b.th.Faint.Render(strings.Join(parts, " • ")))
+	return sb.String()
+}
+
+func runBrowse(args []string) error {
+	fs := flag.NewFlagSet("browse", flag.ExitOnError)
+	by := fs.String("sort", "due", "initial order: "+strings.Join(cardSorts, "|"))
+	reverse := fs.Bool("reverse", false, "reverse the sort order")
+	_ = fs.Parse(args)
+	if _, err := cardLess(*by); err != nil {
+		return err
+	}
+	q, err := queryArg(fs.Args())
+	if err != nil {
+		return err
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	th, _ := themeByName(cfg.Review.Theme)
+	cards, idx, err := selectCards(q)
+	if err != nil {
+		return err
+	}
+	sel := []Card{}
+	for _, i := range idx {
+		sel = append(sel, cards[i])
+	}
+	_, err = tea.NewProgram(newBrowser(sel, *by, *reverse, q.Src, th), tea.WithAltScreen()).Run()
+	return err
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"memento/pkg/srs"
+)
+
+// bulkEdit is one batch of changes applied to every card a query selects.
+type bulkEdit struct {
+	addTags, removeTags []string
+	suspend, unsuspend  bool
+	box                 int // 0 = leave alone
+	dueNow              bool
+	pattern             *string // answer pattern to set ("" clears); nil = leave alone
+	strictness          *string // per-card strictness ("" = back to review.strictness)
+}
+
+func (e bulkEdit) empty() bool {
+	return len(e.addTags) == 0 && len(e.removeTags) == 0 && !e.suspend && !e.unsuspend && e.box == 0 && !e.dueNow && e.pattern == nil && e.strictness == nil
+}
+
+// apply edits c in place and reports whether anything changed.
+func (e bulkEdit) apply(c *Card, now time.Time) bool {
+	changed := retag(c, e.addTags, e.removeTags)
+	if e.suspend && !c.Suspended || e.unsuspend && c.Suspended {
+		c.Suspended = e.suspend
+		changed = true
+	}
+	if e.box != 0 && c.Box != e.box {
+		c.Box = e.box
+		changed = true
+	}
+	if e.dueNow && c.NextDue.After(now) {
+		c.NextDue = now
+		changed = true
+	}
+	if e.pattern != nil && c.AnswerPattern != *e.pattern {
+		c.AnswerPattern = *e.pattern
+		changed = true
+	}
+	if e.strictness != nil && c.Strictness != *e.strictness {
+		c.Strictness = *e.strictness
+		changed = true
+	}
+	return changed
+}
+
+func runBulk(args []string) error {
+	fs := flag.NewFlagSet("bulk", flag.ExitOnError)
+	query := fs.String("query", "", `cards to edit, e.g. "tag:docker box:1" (required)`)
+	setTag := fs.String("set-tag", "", "comma-separated tags to add")
+	unsetTag := fs.String("unset-tag", "", "comma-separated tags to remove")
+	suspend := fs.Bool("suspend", false, "suspend the cards (never due until unsuspended)")
+	unsuspend := fs.Bool("unsuspend", false, "unsuspend the cards")
+	box := fs.Int("box", 0, fmt.Sprintf("move the cards to this Leitner box (1-%d)", srs.MaxBox()))
+	dueNow := fs.Bool("due-now", false, "make the cards due immediately")
+	pattern := fs.String("answer-pattern", "", "accept any answer matching this glob (-j*) or /regex/; \"off\" clears it")
+	strict := fs.String("strictness", "", "answer matching for these cards: exact, case-insensitive, fuzzy, substring, or \"default\" for review.strictness")
+	dry := fs.Bool("dry-run", false, "only list the cards that would change")
+	_ = fs.Parse(args)
+
+	if *query == "" {
+		return fmt.Errorf("bulk needs --query (use --query 'is:new' etc.; there is no implicit \"all\")")
+	}
+	if *suspend && *unsuspend {
+		return fmt.Errorf("--suspend and --unsuspend are mutually exclusive")
+	}
+	if *box < 0 || *box > srs.MaxBox() {
+		return fmt.Errorf("--box must be between 1 and %d", srs.MaxBox())
+	}
+	e := bulkEdit{addTags: splitList(*setTag), removeTags: splitList(*unsetTag), suspend: *suspend, unsuspend: *unsuspend, box: *box, dueNow: *dueNow}
+	switch *pattern {
+	case "":
+	case "off":
+		e.pattern = new(string)
+	default:
+		if _, err := compilePattern(*pattern); err != nil {
+			return err
+		}
+		e.pattern = pattern
+	}
+	switch *strict {
+	case "":
+	case "default":
+		e.strictness = new(string)
+	default:
+		if err := checkStrictness(*strict); err != nil {
+			return err
+		}
+		e.strictness = strict
+	}
+	if e.empty() {
+		return fmt.Errorf("nothing to do: pass --set-tag, --unset-tag, --suspend, --unsuspend, --box, --due-now, --answer-pattern or --strictness")
+	}
+	q, err := ParseQuery(*query)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	changed, matched := 0, 0
+	err = saveUndoable(undoEntry{What: "bulk " + q.Src}, func(cards []Card) []Card {
+		for i, c := range cards {
+			if !q.Match(c, now) {
+				continue
+			}
+			matched++
+			if e.apply(&c, now) {
+				changed++
+				printCardRow(c)
+				cards[i] = c
+			}
+		}
+		if *dry || changed == 0 {
+			return nil
+		}
+		return cards
+	})
+	if err != nil {
+		return err
+	}
+	if *dry {
+		fmt.Printf("%d of %d matching cards would change\n", changed, matched)
+		return nil
+	}
+	fmt.Printf("Updated %d of %d matching cards\n", changed, matched)
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"memento/pkg/ingest"
+	"memento/pkg/storage"
+)
+
+type CaptureEntry = ingest.CaptureEntry
+
+func capturePath() (string, error) {
+	p, err := storage.CardsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "capture.jsonl"), nil
+}
+
+func appendCapture(e CaptureEntry) error {
+	p, err := capturePath()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runCapture(args []string) error {
+	fs := flag.NewFlagSet("capture", flag.ExitOnError)
+	shell := fs.String("shell", "", "shell the command ran in")
+	cwd := fs.String("cwd", "", "working directory the command ran in")
+	_ = fs.Parse(args)
+	raw := strings.Join(fs.Args(), " ")
+	if isSensitive(raw) {
+		return nil // not even a scrubbed copy
+	}
+	cmd := strings.TrimSpace(scrub(raw))
+	if cmd == "" || strings.HasPrefix(cmd, "memento ") {
+		return nil
+	}
+	e := CaptureEntry{When: time.Now(), Shell: *shell, Command: cmd}
+	if *cwd != "" {
+		e.Cwd, e.Project = storage.TildePath(*cwd), ingest.DetectProject(*cwd)
+	}
+	return appendCapture(e)
+}
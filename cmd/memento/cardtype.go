@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+
+	"memento/pkg/cards"
+)
+
+// typeSpec is how the review asks for and grades one card type.
+type typeSpec struct {
+	label       string // shown in the review header
+	placeholder string // answer input placeholder
+	long        bool   // multi-line input
+	command     bool   // the answer is a whole command: normalized, scored per token
+	plain       bool   // text-matched: alternatives, answer patterns and flag notes apply
+	complete    bool   // tab completion from the tool's vocabulary
+	check       func(c Card, ans string) bool
+}
+
+var cardTypes = map[CardType]typeSpec{
+	cards.Cloze:          {label: "fill the blank", placeholder: "your answer (flag/word)", plain: true, complete: true, check: checkText},
+	cards.Reverse:        {label: "type the command", placeholder: "type the whole command", long: true, command: true, plain: true, check: checkCommand},
+	cards.FullRecall:     {label: "recall the answer", placeholder: "type the whole answer", long: true, plain: true, check: checkText},
+	cards.Sequence:       {label: "what comes next", placeholder: "type the whole command", long: true, command: true, plain: true, check: checkCommand},
+	cards.Comprehension:  {label: "describe it", placeholder: "a few words", check: checkDescription},
+	cards.MultipleChoice: {label: "pick one", placeholder: "choice number", check: checkComprehension},
+	cards.Order:          {label: "put in order", placeholder: "stage numbers, e.g. 2 3 1", check: func(c Card, ans string) bool { return checkOrder(c.Answer, ans) }},
+}
+
+// specFor is the spec of c's type; unknown types (a newer build's cards) review as cloze.
+func specFor(c Card) typeSpec {
+	if s, ok := cardTypes[c.TypeOf()]; ok {
+		return s
+	}
+	return cardTypes[cards.Cloze]
+}
+
+// checkText matches against the answer, its alternatives and any answer
+// pattern, at the card's strictness.
+func checkText(c Card, ans string) bool {
+	if matchPattern(c, ans) {
+		return true
+	}
+	level := strictnessFor(c)
+	for _, want := range accepted(c) {
+		if answerMatches(want, ans, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCommand is checkText on a whole-command answer, normalized like the card's.
+func checkCommand(c Card, ans string) bool { return checkText(c, normalizeCommand(ans)) }
+
+// checkDescription passes a free-text description that names enough of the
+// expected one's key words or overlaps it enough.
+func checkDescription(c Card, ans string) bool {
+	for _, want := range accepted(c) {
+		if checkKeywords(want, ans) || tokenOverlap(strings.ToLower(want), strings.ToLower(ans)) >= 0.5 {
+			return true
+		}
+	}
+	return false
+}
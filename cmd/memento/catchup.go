@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// spreadBacklog reschedules due cards over days: the highest-priority share
+// (most overdue, weakest) stays due today, the rest move to the start of each
+// following review day. It returns how many cards land on each day.
+func spreadBacklog(cards []Card, days int, now time.Time) []int {
+	due := DueCards(cards, now) // sorted by srs.Priority
+	perDay := (len(due) + days - 1) / days
+	slot := map[string]int{}
+	for i, c := range due {
+		slot[c.ID] = i / max(perDay, 1)
+	}
+	today := dayStart(now)
+	counts := make([]int, days)
+	for i := range cards {
+		k, ok := slot[cards[i].ID]
+		if !ok {
+			continue
+		}
+		counts[k]++
+		if k > 0 {
+			cards[i].NextDue = today.AddDate(0, 0, k)
+		}
+	}
+	return counts
+}
+
+func runCatchUp(args []string) error {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("catchup", flag.ExitOnError)
+	days := fs.Int("days", cfg.Review.CatchUpDays, "spread the backlog over this many days (review.catch_up_days)")
+	dry := fs.Bool("dry-run", false, "only print the plan")
+	_ = fs.Parse(args)
+	if *days < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+	now := time.Now()
+	var counts []int
+	total := 0
+	err = updateCards(func(cards []Card) []Card {
+		counts = spreadBacklog(cards, *days, now)
+		for _, n := range counts {
+			total += n
+		}
+		if total == 0 || *dry {
+			return nil
+		}
+		return cards
+	})
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		fmt.Println("Nothing due — no backlog to spread.")
+		return nil
+	}
+	fmt.Printf("Spreading %d due cards over %d days (most overdue and weakest first):\n", total, *days)
+	for k, n := range counts {
+		if n > 0 {
+			fmt.Printf("  %s  %d cards\n", now.AddDate(0, 0, k).Format("Mon 2006-01-02"), n)
+		}
+	}
+	if !*dry {
+		logger.Info("catchup", "cards", total, "days", *days)
+	}
+	return nil
+}
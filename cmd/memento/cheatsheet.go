@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"memento/pkg/srs"
+)
+
+// Cheat sheet: mastered cards rendered to a single printable HTML page.
+// For a PDF, print it from a browser (the stylesheet is print-friendly).
+
+var cheatsheetTmpl = template.Must(template.New("cheatsheet").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="generator" content="{{.Generator}}"><title>{{.Title}}</title>
+<style>
+body{font:10pt/1.3 system-ui,sans-serif;margin:1.5em;columns:2;column-gap:2em}
+h1{column-span:all;font-size:14pt;margin:0 0 .5em}
+section{break-inside:avoid;margin-bottom:1em}
+h2{font-size:11pt;border-bottom:1px solid #999;margin:0 0 .3em}
+code{font:9pt ui-monospace,monospace;white-space:pre-wrap}
+ul{list-style:none;padding:0;margin:0}
+li{margin:.15em 0}
+.ans{font-weight:bold}
+footer{column-span:all;color:#777;font-size:8pt;margin-top:1em}
+@media print{body{margin:0}}
+</style></head><body>
+<h1>{{.Title}}</h1>
+{{range .Groups}}<section><h2>{{.Tool}}</h2><ul>
+{{range .Cards}}<li><code>{{.Command}}</code> — <span class="ans"><code>{{.Answer}}</code></span></li>
+{{end}}</ul></section>
+{{end}}<footer>Generated by memento on {{.Date}} · {{.Count}} mastered cards</footer>
+</body></html>
+`))
+
+type cheatGroup struct {
+	Tool  string
+	Cards []Card
+}
+
+func toolOf(c Card) string {
+	if f := strings.Fields(c.Command); len(f) > 0 {
+		return f[0]
+	}
+	return "misc"
+}
+
+func WriteCheatsheet(w io.Writer, cards []Card, title string, now time.Time) error {
+	byTool := map[string][]Card{}
+	for _, c := range cards {
+		byTool[toolOf(c)] = append(byTool[toolOf(c)], c)
+	}
+	groups := make([]cheatGroup, 0, len(byTool))
+	for tool, cs := range byTool {
+		sort.SliceStable(cs, func(i, j int) bool { return cs[i].Command < cs[j].Command })
+		groups = append(groups, cheatGroup{tool, cs})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Tool < groups[j].Tool })
+	return cheatsheetTmpl.Execute(w, map[string]any{
+		"Title": title, "Groups": groups, "Count": len(cards), "Date": now.Format("2006-01-02"), "Generator": buildInfo().String(),
+	})
+}
+
+func runCheatsheet(args []string) error {
+	fs := flag.NewFlagSet("cheatsheet", flag.ExitOnError)
+	tag := fs.String("tag", "", "only include cards with this tag")
+	minBox := fs.Int("min-box", max(srs.MaxBox()-1, 1), "minimum Leitner box to count as mastered")
+	out := fs.String("out", "cheatsheet.html", "output file (\"-\" = stdout)")
+	_ = fs.Parse(args)
+
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	cards = filterCards(cards, func(c Card) bool {
+		return c.Box >= *minBox && (*tag == "" || hasTag(c, *tag))
+	})
+	if len(cards) == 0 {
+		return fmt.Errorf("no mastered cards (box ≥ %d) to put on a cheat sheet yet", *minBox)
+	}
+	title := "Memento cheat sheet"
+	if *tag != "" {
+		title += " — " + *tag
+	}
+	if *out == "-" {
+		return WriteCheatsheet(os.Stdout, cards, title, time.Now())
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := WriteCheatsheet(f, cards, title, time.Now()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d cards to %s\n", len(cards), *out)
+	return nil
+}
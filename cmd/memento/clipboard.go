@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardTools are the paste/copy helpers tried in order on each platform.
+func clipboardTools() (pasters, copiers [][]string) {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}, [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard -Raw"}}, [][]string{{"clip.exe"}}
+	}
+	pasters = [][]string{{"xclip", "-selection", "clipboard", "-o"}, {"xsel", "--clipboard", "--output"}, {"termux-clipboard-get"}}
+	copiers = [][]string{{"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}, {"termux-clipboard-set"}}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		pasters = append([][]string{{"wl-paste", "--no-newline"}}, pasters...)
+		copiers = append([][]string{{"wl-copy"}}, copiers...)
+	}
+	return pasters, copiers
+}
+
+var errNoClipboard = errors.New("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+
+func readClipboard() (string, error) {
+	pasters, _ := clipboardTools()
+	for _, t := range pasters {
+		if _, err := exec.LookPath(t[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(t[0], t[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", t[0], err)
+		}
+		return string(out), nil
+	}
+	return "", errNoClipboard
+}
+
+func writeClipboard(s string) error {
+	_, copiers := clipboardTools()
+	for _, t := range copiers {
+		if _, err := exec.LookPath(t[0]); err != nil {
+			continue
+		}
+		c := exec.Command(t[0], t[1:]...)
+		c.Stdin = strings.NewReader(s)
+		var stderr bytes.Buffer
+		c.Stderr = &stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("%s: %v %s", t[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+	return errNoClipboard
+}
+
+// osc52 asks the terminal itself to set the clipboard (works over SSH, where
+// no local clipboard tool can reach the user's desktop).
+func osc52(s string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(s)) + "\a"
+}
+
+// clipboardCommand turns pasted text into one command line: prompt markers
+// ("$ ", "% ", "> ") and backslash continuations from wikis and chat are undone.
+func clipboardCommand(s string) (string, error) {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\\\n", " ")
+	lines := []string{}
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+		for _, p := range []string{"$ ", "% "} {
+			l = strings.TrimSpace(strings.TrimPrefix(l, p))
+		}
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	switch len(lines) {
+	case 0:
+		return "", fmt.Errorf("clipboard is empty")
+	case 1:
+		return lines[0], nil
+	}
+	return "", fmt.Errorf("clipboard holds %d lines; copy a single command", len(lines))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// Combo cards: for tools with subcommands, a sibling of the cloze card that
+// blanks the other half of the subcommand+flag pair, so both directions get
+// drilled (git _____ --autosquash / git rebase _____).
+
+// comboBlank blanks whichever of the subcommand and its key flag (the first
+// long flag, else the first short one) cloze(canon) left visible.
+func comboBlank(canon string) (prompt, answer, hint string, ok bool) {
+	words := strings.Fields(canon)
+	if len(words) < 3 {
+		return "", "", "", false
+	}
+	good := preferSubcommands(words[0])
+	sub, key := -1, -1
+	for i := 1; i < len(words) && sub < 0; i++ {
+		if good[words[i]] {
+			sub = i
+		}
+	}
+	for _, long := range []bool{true, false} {
+		for i := 1; i < len(words) && key < 0; i++ {
+			w := words[i]
+			if strings.HasPrefix(w, "-") && strings.HasPrefix(w, "--") == long && !isBadAnswerToken(w) {
+				key = i
+			}
+		}
+	}
+	if sub < 0 || key < 0 {
+		return "", "", "", false
+	}
+	_, clozeAns, _ := cloze(canon)
+	if clozeAns == words[sub] {
+		prompt, answer = blankAt(words, key)
+		return prompt, answer, "Which flag goes with " + words[sub] + " here?", true
+	}
+	prompt, answer = blankAt(words, sub)
+	return prompt, answer, "Which subcommand takes " + words[key] + "?", true
+}
+
+func comboCard(canon string, now time.Time) (Card, bool) {
+	prompt, answer, hint, ok := comboBlank(canon)
+	if !ok {
+		return Card{}, false
+	}
+	return Card{
+		ID: hash("combo:" + canon), Type: cards.Cloze, Prompt: prompt, Answer: answer, Hint: hint, Command: canon,
+		Tags: unique(append(deriveTags(canon), comboTag)), Box: 1, NextDue: now, SeenCount: 1,
+	}, true
+}
+
+func GenerateComboCards(events []CommandEvent, existing []Card, now time.Time) []Card {
+	have := map[string]bool{}
+	for _, c := range existing {
+		have[c.ID] = true
+	}
+	out := []Card{}
+	for _, ev := range events {
+		if !isTricky(ev.Command) {
+			continue
+		}
+		c, ok := comboCard(normalizeCommand(ev.Command), now)
+		if !ok || have[c.ID] {
+			continue
+		}
+		have[c.ID] = true
+		c.Origins = mergeOrigin(nil, ev.Origin)
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// completionIndex maps each tool to the flags/subcommands seen across the
+// corpus, sorted alphabetically so the list says nothing about which one a
+// given card wants.
+func completionIndex(cards []Card) map[string][]string {
+	sets := map[string]map[string]bool{}
+	for _, c := range cards {
+		if c.Kind() != "cloze" {
+			continue
+		}
+		tool := toolOf(c)
+		if sets[tool] == nil {
+			sets[tool] = map[string]bool{}
+		}
+		words := strings.Fields(c.Command)
+		for _, w := range words[1:] {
+			if len(w) > 1 && !isBadAnswerToken(w) && w != "|" {
+				sets[tool][w] = true
+			}
+		}
+		if a, _, _ := flagValue(c.Answer); a != "" {
+			sets[tool][a] = true
+		}
+	}
+	out := map[string][]string{}
+	for tool, set := range sets {
+		words := make([]string, 0, len(set))
+		for w := range set {
+			words = append(words, w)
+		}
+		sort.Strings(words)
+		out[tool] = words
+	}
+	return out
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// Comprehension cards show a whole command and ask what it does. Descriptions
+// come from tldr examples, an optional external enricher (e.g. an LLM CLI),
+// the bundled flag descriptions, or whatis(1) as a last resort.
+
+const (
+	comprehensionChoices = 4
+)
+
+// describeFromTldr picks the tldr example sharing the most tokens with cmd.
+func describeFromTldr(cmd string) string {
+	f := strings.Fields(cmd)
+	if len(f) == 0 {
+		return ""
+	}
+	page, err := fetchTldrPage(f[0])
+	if err != nil {
+		return ""
+	}
+	best, bestScore := "", 0.5
+	for _, ex := range parseTldr(page) {
+		if s := tokenOverlap(cmd, ex.Command); s >= bestScore && ex.Description != "" {
+			best, bestScore = ex.Description, s
+		}
+	}
+	return best
+}
+
+// tokenOverlap is the Jaccard similarity of the whitespace tokens of a and b.
+func tokenOverlap(a, b string) float64 {
+	ta, tb := map[string]bool{}, map[string]bool{}
+	for _, t := range strings.Fields(a) {
+		ta[t] = true
+	}
+	for _, t := range strings.Fields(b) {
+		tb[t] = true
+	}
+	inter := 0
+	for t := range ta {
+		if tb[t] {
+			inter++
+		}
+	}
+	if u := len(ta) + len(tb) - inter; u > 0 {
+		return float64(inter) / float64(u)
+	}
+	return 0
+}
+
+// describeExternal runs the configured enricher with the command on stdin.
+func describeExternal(shellCmd, cmd string) string {
+	if shellCmd == "" {
+		return ""
+	}
+	c := exec.Command("sh", "-c", shellCmd)
+	c.Stdin = strings.NewReader(cmd)
+	out, err := c.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+}
+
+func describeFromMan(cmd string) string {
+	f := strings.Fields(cmd)
+	if len(f) == 0 {
+		return ""
+	}
+	out, err := exec.Command("whatis", f[0]).Output()
+	if err != nil {
+		return ""
+	}
+	line := strings.SplitN(string(out), "\n", 2)[0]
+	if i := strings.Index(line, " - "); i >= 0 {
+		return "Runs " + f[0] + ": " + strings.TrimSpace(line[i+3:])
+	}
+	return ""
+}
+
+// flagParts splits cmd into its tool (and subcommand) and the descriptions
+// of the flags the bundled table knows, in command order.
+func flagParts(cmd string) (head string, descs []string) {
+	words := strings.Fields(cmd)
+	keys := toolKeys(words)
+	if len(keys) == 0 {
+		return "", nil
+	}
+	seen := map[string]bool{}
+	for _, w := range words[1:] {
+		if d := describeFlag(words, w); d != "" && !seen[d] {
+			seen[d] = true
+			descs = append(descs, d)
+		}
+	}
+	return keys[len(keys)-1], descs
+}
+
+// describeFromFlags describes cmd offline from its flags' descriptions.
+func describeFromFlags(cmd string) string {
+	head, descs := flagParts(cmd)
+	if len(descs) == 0 {
+		return ""
+	}
+	return head + ": " + strings.Join(descs, "; ")
+}
+
+// flagDistractors are near-miss descriptions of cmd for multiple choice: one
+// flag's meaning swapped for that of another flag of the same tool.
+func flagDistractors(cmd string) []string {
+	head, descs := flagParts(cmd)
+	if len(descs) == 0 {
+		return nil
+	}
+	used := set(descs...)
+	others := []string{}
+	for _, k := range toolKeys(strings.Fields(cmd)) {
+		for _, d := range descriptions()[k] {
+			if !used[d] {
+				used[d] = true
+				others = append(others, d)
+			}
+		}
+	}
+	sort.Strings(others)
+	out := []string{}
+	for i := range descs {
+		for _, o := range others {
+			swapped := append([]string{}, descs...)
+			swapped[i] = o
+			out = append(out, head+": "+strings.Join(swapped, "; "))
+		}
+	}
+	return out
+}
+
+func describeCommand(cfg Config, cmd string) string {
+	if d := describeFromTldr(cmd); d != "" {
+		return d
+	}
+	if d := describeExternal(cfg.Enrich.Command, cmd); d != "" {
+		return d
+	}
+	if d := describeFromFlags(cmd); d != "" {
+		return d
+	}
+	return describeFromMan(cmd)
+}
+
+// comprehensionCard builds the prompt with shuffled choices (deterministic per card).
+func comprehensionCard(cmd, desc string, distractors []string, now time.Time) Card {
+	id := hash("comp:" + cmd)
+	c := Card{
+		ID: id, Type: cards.MultipleChoice, Answer: desc, Hint: "Pick the number of the matching description", Command: cmd,
+		Tags: unique(append(deriveTags(cmd), comprehensionTag)), Box: 1, NextDue: now, SeenCount: 1,
+	}
+	seed, _ := strconv.ParseInt(id[:15], 16, 64)
+	r := rand.New(rand.NewSource(seed))
+	pool := []string{}
+	for _, d := range distractors {
+		if d != desc {
+			pool = append(pool, d)
+		}
+	}
+	r.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	if len(pool) > comprehensionChoices-1 {
+		pool = pool[:comprehensionChoices-1]
+	}
+	if len(pool) == 0 {
+		c.Prompt = "What does this do?\n  " + cmd
+		c.Type, c.Hint = cards.Comprehension, "Describe it in a few words"
+		return c
+	}
+	c.Choices = append(pool, desc)
+	r.Shuffle(len(c.Choices), func(i, j int) { c.Choices[i], c.Choices[j] = c.Choices[j], c.Choices[i] })
+	var b strings.Builder
+	b.WriteString("What does this do?\n  " + cmd + "\n")
+	for i, ch := range c.Choices {
+		fmt.Fprintf(&b, "\n  %d) %s", i+1, ch)
+	}
+	c.Prompt = b.String()
+	return c
+}
+
+// checkComprehension accepts the choice number or a close-enough description.
+func checkComprehension(c Card, ans string) bool {
+	if n, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(ans, ")"))); err == nil {
+		return n >= 1 && n <= len(c.Choices) && c.Choices[n-1] == c.Answer
+	}
+	return tokenOverlap(strings.ToLower(c.Answer), strings.ToLower(ans)) >= 0.5
+}
+
+func runEnrich(args []string) error {
+	fs := flag.NewFlagSet("enrich", flag.ExitOnError)
+	tag := fs.String("tag", "", "only enrich cards with this tag")
+	limit := fs.Int("limit", 50, "max commands to look up")
+	_ = fs.Parse(args)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	have := map[string]bool{}
+	known := []string{}
+	for _, c := range cards {
+		have[c.ID] = true
+		if hasTag(c, comprehensionTag) {
+			known = append(known, c.Answer)
+		}
+	}
+
+	type found struct{ cmd, desc string }
+	var todo []found
+	for _, c := range cards {
+		if len(todo) >= *limit {
+			break
+		}
+		if *tag != "" && !hasTag(c, *tag) {
+			continue
+		}
+		if c.Kind() != "cloze" || have[hash("comp:"+c.Command)] {
+			continue
+		}
+		if d := describeCommand(cfg, c.Command); d != "" {
+			todo = append(todo, found{c.Command, d})
+			have[hash("comp:"+c.Command)] = true
+			known = append(known, d)
+		}
+	}
+
+	now := time.Now()
+	made := []Card{}
+	for _, f := range todo {
+		pool := known
+		if f.desc == describeFromFlags(f.cmd) {
+			if ds := flagDistractors(f.cmd); len(ds) >= comprehensionChoices-1 {
+				pool = ds // same tool, one flag off: harder than unrelated commands
+			}
+		}
+		made = append(made, comprehensionCard(f.cmd, f.desc, pool, now))
+	}
+	// looking commands up can take a while: add to the deck as it is now
+	if _, _, err := addCards(made); err != nil {
+		return err
+	}
+	fmt.Printf("Added %d comprehension cards.\n", len(todo))
+	return nil
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"memento/pkg/ingest"
+	"memento/pkg/srs"
+	"memento/pkg/storage"
+)
+
+// Config is read from config.json in the XDG config dir. Missing file → defaults.
+type Config struct {
+	Ingest   IngestConfig        `json:"ingest"`
+	Scrub    ScrubConfig         `json:"scrub"`
+	Webhook  WebhookConfig       `json:"webhook"`
+	Discover DiscoverConfig      `json:"discover"`
+	Review   ReviewConfig        `json:"review"`
+	Enrich   EnrichConfig        `json:"enrich"`
+	Keys     map[string][]string `json:"keys"` // review TUI rebinding: action → keys
+	Display  DisplayConfig       `json:"display"`
+	Log      LogConfig           `json:"log"`
+	KB       KBConfig            `json:"kb"`
+	Storage  StorageConfig       `json:"storage"`
+}
+
+type StorageConfig struct {
+	Backend string `json:"backend"`        // json (default) or sqlite
+	Path    string `json:"path,omitempty"` // sqlite database (default memento.db in the data dir)
+}
+
+type KBConfig struct {
+	URL string `json:"url"` // memento kb update fetches this (and <url>.sha256)
+}
+
+// DisplayConfig: times are shown relative ("due in 3d") unless absolute_times;
+// review days follow timezone (default TZ) and start at day_start_hour.
+type DisplayConfig struct {
+	AbsoluteTimes bool   `json:"absolute_times"`
+	DayStartHour  int    `json:"day_start_hour"`     // review days start at this local hour (0-23, e.g. 4)
+	Timezone      string `json:"timezone,omitempty"` // IANA zone; default TZ / the system zone
+}
+
+type EnrichConfig struct {
+	Command string `json:"command"` // shell command: command on stdin → one-line description on stdout
+}
+
+type ReviewConfig struct {
+	ShowContext      bool                   `json:"show_context"`       // show cwd/project the command was captured in
+	SkipMissingTools bool                   `json:"skip_missing_tools"` // hide cards for tools not on PATH
+	SkipTutorial     bool                   `json:"skip_tutorial"`      // don't add the memento-tutorial deck on the first review
+	Theme            string                 `json:"theme"`              // default|high-contrast|deuteranopia|protanopia
+	CatchUpDays      int                    `json:"catch_up_days"`      // memento catchup: days to spread a backlog over
+	Snooze           string                 `json:"snooze,omitempty"`   // default snooze span in the review TUI (1d)
+	ShowExample      bool                   `json:"show_example"`       // after answering, show a real run of the command
+	Relearn          bool                   `json:"relearn"`            // re-ask failed cards at the end of the session (default on)
+	Speed            SpeedConfig            `json:"speed"`
+	AutoAdvance      bool                   `json:"auto_advance"`       // next card by itself after a correct answer
+	AutoAdvanceDelay Duration               `json:"auto_advance_delay"` // default 800ms
+	Tags             map[string]TagSchedule `json:"tags,omitempty"`
+	NewPerSession    int                    `json:"new_per_session"`      // never-answered cards per session (-1 = no cap)
+	MaxPerDay        int                    `json:"max_per_day"`          // hard cap on graded reviews per review day (0 = none)
+	Boxes            []Duration             `json:"boxes,omitempty"`      // Leitner ladder, box 1 first (default 0, 1d, 3d, 7d, 21d)
+	PartialPass      float64                `json:"partial_pass"`         // share of blanks/tokens a multi-part answer needs to pass (graded hard below 1)
+	Strictness       string                 `json:"strictness"`           // answer matching: exact, case-insensitive (default), fuzzy or substring
+	Scheduler        string                 `json:"scheduler"`            // leitner (default) or fsrs
+	Schedulers       map[string]string      `json:"schedulers,omitempty"` // per tag, e.g. {"discover": "fsrs"}; alphabetically first tag wins
+	FSRSRetention    float64                `json:"fsrs_retention"`       // target recall for fsrs cards (default 0.9)
+	OverdueAware     bool                   `json:"overdue_aware"`        // late answers count: extra promotion when right, no demotion when very late and wrong
+	NewPosition      string                 `json:"new_position"`         // mixed|first|last
+	Fuzz             float64                `json:"fuzz"`                 // ± fraction added to intervals of a day or more (default 0.1, 0 = off)
+	FuzzSeed         int64                  `json:"fuzz_seed"`            // fixed seed for repeatable fuzz (0 = random)
+	PickUpNew        bool                   `json:"pick_up_new"`          // cards ingested or falling due during a review join its queue
+}
+
+// TagSchedule adjusts intervals for cards carrying a tag, e.g.
+// {"danger": {"multiplier": 0.3}, "trivia": {"intervals": ["1d", "3d", "2w", "30d", "90d"]}}.
+type TagSchedule struct {
+	Intervals  []Duration `json:"intervals,omitempty"`  // one per box, replaces the ladder
+	Multiplier float64    `json:"multiplier,omitempty"` // scales the (possibly replaced) interval
+}
+
+// SpeedConfig stretches or shrinks the interval of a correct answer by how
+// long it took: under Fast earns FastBonus, over Slow only SlowFactor.
+type SpeedConfig struct {
+	Enabled    bool     `json:"enabled"`
+	Fast       Duration `json:"fast"`
+	Slow       Duration `json:"slow"`
+	FastBonus  float64  `json:"fast_bonus"`
+	SlowFactor float64  `json:"slow_factor"`
+}
+
+type IngestConfig struct {
+	SensitiveCommands []string           `json:"sensitive_commands"` // replaces the built-in list
+	LineFormats       []LineFormatConfig `json:"line_formats"`       // tried before the built-in detectors
+	Rules             RulesConfig        `json:"rules"`
+	HistoryFiles      []string           `json:"history_files,omitempty"` // replaces the guessed history files
+	Masking           string             `json:"masking,omitempty"`       // off|minimal|domain|standard|paranoid (see maskingLevels)
+	LongFlags         bool               `json:"long_flags"`              // normalize -R to --recursive (see memento flags); rehash after changing
+	AcceptScore       float64            `json:"accept_score"`            // cards scoring below this (0..1) wait for memento triage; 0 accepts all but junk
+	MaxPerTool        map[string]int     `json:"max_per_tool,omitempty"`  // most cards a tool may hold in the deck, "*" for unlisted tools, e.g. {"git": 100, "*": 30}
+}
+
+// RulesConfig holds expr-lang expressions evaluated during ingest (see rules.go).
+type RulesConfig struct {
+	Tricky string `json:"tricky"` // bool: should this command become a card?
+	Cloze  string `json:"cloze"`  // string: which token to blank
+}
+
+// LineFormatConfig describes a custom history line, e.g.
+// {"regex": "^(?P<ts>\\d+) (?P<cmd>.*)$", "time_layout": "epoch"}.
+type LineFormatConfig struct {
+	Regex      string `json:"regex"`
+	TimeLayout string `json:"time_layout"` // Go layout, or "epoch" (default)
+}
+
+type ScrubConfig struct {
+	GitleaksConfig string `json:"gitleaks_config"` // path to a gitleaks.toml with extra secret rules
+	DropSecrets    bool   `json:"drop_secrets"`    // skip commands with secrets instead of redacting them
+}
+
+type DiscoverConfig struct {
+	NewPerSession int `json:"new_per_session"` // unseen discover cards per review (-1 = unlimited)
+}
+
+type WebhookConfig struct {
+	URL       string   `json:"url"`
+	Format    string   `json:"format"`    // json|slack|discord
+	Every     Duration `json:"every"`     // post on a schedule (0 = off)
+	Threshold int      `json:"threshold"` // post when due count crosses this (0 = off)
+}
+
+// Duration unmarshals from strings like "6h", "30m" or whole days ("3d", "2w").
+type Duration time.Duration
+
+func durations(ds []Duration) []time.Duration {
+	out := make([]time.Duration, len(ds))
+	for i, d := range ds {
+		out[i] = time.Duration(d)
+	}
+	return out
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) { return json.Marshal(time.Duration(d).String()) }
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*d = 0
+		return nil
+	}
+	for _, u := range []struct {
+		suffix string
+		unit   time.Duration
+	}{{"d", 24 * time.Hour}, {"w", 7 * 24 * time.Hour}} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, u.suffix)); err == nil && strings.HasSuffix(s, u.suffix) {
+			*d = Duration(time.Duration(n) * u.unit)
+			return nil
+		}
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func defaultConfig() Config {
+	return Config{
+		Ingest:   IngestConfig{SensitiveCommands: defaultSensitiveCommands, LongFlags: true, AcceptScore: 0.5},
+		Webhook:  WebhookConfig{Format: "json"},
+		Discover: DiscoverConfig{NewPerSession: 5},
+		Log:      LogConfig{Level: "info", MaxSizeMB: 5, Keep: 3},
+		KB:       KBConfig{URL: defaultKBURL},
+		Review: ReviewConfig{CatchUpDays: 7, Relearn: true, OverdueAware: true, Scheduler: "leitner", FSRSRetention: 0.9, Strictness: strictNoCase, PartialPass: 0.75, Fuzz: 0.1, NewPerSession: 20, NewPosition: "mixed", Speed: SpeedConfig{
+			Enabled: true, Fast: Duration(4 * time.Second), Slow: Duration(20 * time.Second), FastBonus: 1.2, SlowFactor: 0.6,
+		}},
+	}
+}
+
+func configDir() (string, error) {
+	if d := os.Getenv("XDG_CONFIG_HOME"); d != "" {
+		return filepath.Join(d, "memento"), nil
+	}
+	h, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(h, ".config", "memento"), nil
+}
+
+func configPath() (string, error) {
+	d, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "config.json"), nil
+}
+
+func LoadConfig() (Config, error) {
+	cfg := defaultConfig()
+	p, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// SaveConfigFields sets only the given "section.key" fields in the config
+// file and leaves the rest as written, so defaults that were never written
+// (like ingest.sensitive_commands) keep following the built-ins.
+func SaveConfigFields(fields map[string]any) error {
+	p, err := configPath()
+	if err != nil {
+		return err
+	}
+	top := map[string]json.RawMessage{}
+	b, err := os.ReadFile(p)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &top); err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	for k, v := range fields {
+		section, key, _ := strings.Cut(k, ".")
+		sec := map[string]json.RawMessage{}
+		if raw, ok := top[section]; ok {
+			if err := json.Unmarshal(raw, &sec); err != nil {
+				return fmt.Errorf("%s: %s: %w", p, section, err)
+			}
+		}
+		if sec[key], err = json.Marshal(v); err != nil {
+			return err
+		}
+		if top[section], err = json.Marshal(sec); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	b, err = json.MarshalIndent(top, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(p, append(b, '\n'), 0o644)
+}
+
+// configureScrub sets up only what scrubbing needs: the sensitive-command
+// list and the secret rules. memento capture, run from the shell hook after
+// every command, stops there.
+func configureScrub(cfg Config) error {
+	sensitiveCommands = cfg.Ingest.SensitiveCommands
+	dropSecrets = cfg.Scrub.DropSecrets
+	secretRules = builtinSecretRules
+	if p := gitleaksPath(cfg); p != "" {
+		extra, err := LoadGitleaksRules(p)
+		if err != nil {
+			return err
+		}
+		secretRules = append(append([]secretRule{}, builtinSecretRules...), extra...)
+	}
+	return nil
+}
+
+// configure applies config knobs that live in package state (used by ingest helpers).
+func configure(cfg Config) error {
+	if _, err := keyMapFromConfig(cfg.Keys); err != nil {
+		return err
+	}
+	if _, err := themeByName(cfg.Review.Theme); err != nil {
+		return fmt.Errorf("review.theme: %w", err)
+	}
+	if cfg.Review.Snooze != "" {
+		if _, err := snoozeUntil(cfg.Review.Snooze, time.Now()); err != nil {
+			return fmt.Errorf("review.snooze: %w", err)
+		}
+	}
+	if s := cfg.Review.Speed; s.Enabled && (s.Fast > s.Slow || s.FastBonus < 1 || s.SlowFactor <= 0 || s.SlowFactor > 1) {
+		return fmt.Errorf("review.speed: want fast <= slow, fast_bonus >= 1 and 0 < slow_factor <= 1")
+	}
+	sp := cfg.Review.Speed
+	srs.Speed = srs.SpeedSettings{Enabled: sp.Enabled, Fast: time.Duration(sp.Fast), Slow: time.Duration(sp.Slow), FastBonus: sp.FastBonus, SlowFactor: sp.SlowFactor}
+	if len(cfg.Review.Boxes) > 0 {
+		if err := srs.SetBoxes(durations(cfg.Review.Boxes)); err != nil {
+			return fmt.Errorf("review.boxes: %w", err)
+		}
+	}
+	srs.OverdueAware = cfg.Review.OverdueAware
+	if err := checkStrictness(cfg.Review.Strictness); err != nil {
+		return fmt.Errorf("review.strictness: %w", err)
+	}
+	strictness = cfg.Review.Strictness
+	if p := cfg.Review.PartialPass; p <= 0 || p > 1 {
+		return fmt.Errorf("review.partial_pass: want more than 0 and at most 1, got %g", p)
+	}
+	partialPass = cfg.Review.PartialPass
+	if err := srs.CheckScheduler(cfg.Review.Scheduler); err != nil {
+		return fmt.Errorf("review.scheduler: %w", err)
+	}
+	for tag, s := range cfg.Review.Schedulers {
+		if err := srs.CheckScheduler(s); err != nil {
+			return fmt.Errorf("review.schedulers.%s: %w", tag, err)
+		}
+	}
+	if r := cfg.Review.FSRSRetention; r < 0.7 || r > 0.99 {
+		return fmt.Errorf("review.fsrs_retention: want 0.7-0.99, got %g", r)
+	}
+	srs.DefaultScheduler, srs.Schedulers, srs.FSRSRetention = cfg.Review.Scheduler, cfg.Review.Schedulers, cfg.Review.FSRSRetention
+	switch cfg.Review.NewPosition {
+	case "mixed", "first", "last":
+	default:
+		return fmt.Errorf("review.new_position: want mixed, first or last, got %q", cfg.Review.NewPosition)
+	}
+	for tag, s := range cfg.Review.Tags {
+		if s.Multiplier < 0 || len(s.Intervals) != 0 && len(s.Intervals) != srs.MaxBox() {
+			return fmt.Errorf("review.tags.%s: want a positive multiplier and/or %d intervals (one per box)", tag, srs.MaxBox())
+		}
+	}
+	srs.TagSchedules = map[string]srs.TagSchedule{}
+	for tag, s := range cfg.Review.Tags {
+		srs.TagSchedules[tag] = srs.TagSchedule{Intervals: durations(s.Intervals), Multiplier: s.Multiplier}
+	}
+	if cfg.Review.Fuzz < 0 || cfg.Review.Fuzz > 0.5 {
+		return fmt.Errorf("review.fuzz: want 0 (off) to 0.5, got %g", cfg.Review.Fuzz)
+	}
+	srs.Fuzz = cfg.Review.Fuzz
+	if cfg.Review.FuzzSeed != 0 {
+		srs.FuzzRand = rand.New(rand.NewSource(cfg.Review.FuzzSeed))
+	}
+	historyFiles = cfg.Ingest.HistoryFiles
+	absoluteTimes = cfg.Display.AbsoluteTimes
+	if h := cfg.Display.DayStartHour; h < 0 || h > 23 {
+		return fmt.Errorf("display.day_start_hour: want 0-23, got %d", h)
+	}
+	srs.DayStartHour = cfg.Display.DayStartHour
+	if tz := cfg.Display.Timezone; tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return fmt.Errorf("display.timezone: %w", err)
+		}
+		time.Local = loc
+	}
+	longFlags = cfg.Ingest.LongFlags
+	if cfg.Ingest.AcceptScore < 0 || cfg.Ingest.AcceptScore > 1 {
+		return fmt.Errorf("ingest.accept_score: %v outside 0..1", cfg.Ingest.AcceptScore)
+	}
+	acceptScore = cfg.Ingest.AcceptScore
+	for tool, n := range cfg.Ingest.MaxPerTool {
+		if n < 0 {
+			return fmt.Errorf("ingest.max_per_tool.%s: want 0 (no cap) or more, got %d", tool, n)
+		}
+	}
+	maxPerTool = cfg.Ingest.MaxPerTool
+	kbURL = cfg.KB.URL
+	if kbURL == "" {
+		kbURL = defaultKBURL
+	}
+	maskingLevel = cfg.Ingest.Masking
+	if maskingLevel == "" {
+		maskingLevel = "standard"
+	}
+	if !slices.Contains(maskingLevels, maskingLevel) {
+		return fmt.Errorf("ingest.masking: unknown level %q (want %s)", maskingLevel, strings.Join(maskingLevels, ", "))
+	}
+	ingest.LineFormats = nil
+	for _, f := range cfg.Ingest.LineFormats {
+		lf, err := ingest.CompileLineFormat(f.Regex, f.TimeLayout)
+		if err != nil {
+			return err
+		}
+		ingest.LineFormats = append(ingest.LineFormats, lf)
+	}
+	if err := compileRules(cfg.Ingest.Rules); err != nil {
+		return err
+	}
+	if err := configureScrub(cfg); err != nil {
+		return err
+	}
+	st, err := storage.Open(cfg.Storage.Backend, cfg.Storage.Path)
+	if err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	store = st
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSaveConfigFields(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	p := filepath.Join(dir, "memento", "config.json")
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(`{"review": {"theme": "protanopia"}, "ingest": {"long_flags": false}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveConfigFields(map[string]any{
+		"ingest.masking":     "paranoid",
+		"scrub.drop_secrets": true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "sensitive_commands") {
+		t.Errorf("defaults were written out:\n%s", b)
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Ingest.Masking != "paranoid" || !cfg.Scrub.DropSecrets {
+		t.Errorf("fields not set: masking %q, drop_secrets %v", cfg.Ingest.Masking, cfg.Scrub.DropSecrets)
+	}
+	if cfg.Review.Theme != "protanopia" || cfg.Ingest.LongFlags {
+		t.Errorf("existing fields lost: theme %q, long_flags %v", cfg.Review.Theme, cfg.Ingest.LongFlags)
+	}
+	if !slices.Equal(cfg.Ingest.SensitiveCommands, defaultSensitiveCommands) {
+		t.Errorf("sensitive_commands = %v, want the built-ins", cfg.Ingest.SensitiveCommands)
+	}
+}
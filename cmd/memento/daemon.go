@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// notifier decides when a webhook post is warranted.
+type notifier struct {
+	cfg      WebhookConfig
+	lastPost time.Time
+	lastDue  int
+}
+
+func (n *notifier) shouldPost(s Summary) bool {
+	if n.cfg.Every > 0 && s.At.Sub(n.lastPost) >= time.Duration(n.cfg.Every) {
+		return true
+	}
+	// crossed upwards since the last check
+	return n.cfg.Threshold > 0 && s.Due >= n.cfg.Threshold && n.lastDue < n.cfg.Threshold
+}
+
+func (n *notifier) tick(now time.Time) error {
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	s := summarize(cards, now)
+	post := n.shouldPost(s)
+	n.lastDue = s.Due
+	if !post {
+		return nil
+	}
+	if err := PostWebhook(n.cfg, s); err != nil {
+		return err
+	}
+	n.lastPost = now
+	return nil
+}
+
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	poll := fs.Duration("poll", time.Minute, "how often to check the card store")
+	once := fs.Bool("once", false, "post a single notification and exit")
+	metrics := fs.String("metrics", "", "serve Prometheus metrics on this address, e.g. :9310")
+	_ = fs.Parse(args)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Webhook.URL == "" && (*metrics == "" || *once) {
+		return fmt.Errorf("daemon: set webhook.url in config.json (or serve --metrics)")
+	}
+	if *once {
+		cards, err := LoadCards()
+		if err != nil {
+			return err
+		}
+		return PostWebhook(cfg.Webhook, summarize(cards, time.Now()))
+	}
+
+	if *metrics != "" {
+		if cfg.Webhook.URL == "" {
+			return serveMetrics(*metrics)
+		}
+		go func() {
+			if err := serveMetrics(*metrics); err != nil {
+				fmt.Fprintln(os.Stderr, "daemon: metrics:", err)
+			}
+		}()
+	}
+	n := &notifier{cfg: cfg.Webhook}
+	for {
+		if err := n.tick(time.Now()); err != nil {
+			fmt.Fprintln(os.Stderr, "daemon:", err)
+		}
+		time.Sleep(*poll)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// Danger-awareness cards: for commands with destructive flags, ask what the
+// flag does and what it will destroy. Scheduled on shorter intervals (see srs.Grade).
+
+type dangerRule struct {
+	tool, flag, what string // tool "" matches any command
+}
+
+var dangerRules = []dangerRule{
+	{"rm", "-rf", "Deletes recursively without prompting; files are gone for good, no trash"},
+	{"rm", "-fr", "Deletes recursively without prompting; files are gone for good, no trash"},
+	{"rm", "-Rf", "Deletes recursively without prompting; files are gone for good, no trash"},
+	{"rm", "--no-preserve-root", "Allows recursive deletion of the root filesystem"},
+	{"git", "--hard", "Discards all uncommitted changes in the working tree and index"},
+	{"git", "--force", "Overwrites the remote branch, discarding commits others pushed"},
+	{"git", "-f", "Forces the operation, overwriting remote commits or untracked files"},
+	{"git", "-D", "Deletes the branch even if it has unmerged commits"},
+	{"git", "-fd", "Deletes untracked files and directories permanently"},
+	{"git", "-fdx", "Deletes untracked and ignored files and directories permanently"},
+	{"git", "--prune", "Removes remote-tracking branches deleted upstream"},
+	{"docker", "prune", "Removes stopped containers, unused networks and dangling images"},
+	{"docker", "--volumes", "Also removes volumes, destroying their data"},
+	{"docker", "-f", "Force-removes running containers or in-use images"},
+	{"kubectl", "--force", "Deletes immediately, bypassing graceful termination"},
+	{"kubectl", "--grace-period=0", "Kills pods without graceful shutdown"},
+	{"kubectl", "--all", "Applies to every resource of that kind in the namespace"},
+	{"rsync", "--delete", "Deletes destination files that are missing from the source"},
+	{"terraform", "-auto-approve", "Applies or destroys infrastructure without confirmation"},
+	{"terraform", "destroy", "Tears down all managed infrastructure"},
+	{"dd", "of=<PATH>", "Overwrites the output file or device byte for byte"},
+	{"", "--force", "Skips safety checks and confirmations"},
+	{"", "--prune", "Deletes items no longer referenced"},
+}
+
+// dangerFlag returns the first destructive flag in cmd and what it does.
+func dangerFlag(cmd string) (flag, what string, ok bool) {
+	toks := strings.Fields(cmd)
+	if len(toks) == 0 {
+		return "", "", false
+	}
+	tool := toks[0]
+	for _, r := range dangerRules {
+		if r.tool != "" && r.tool != tool {
+			continue
+		}
+		for _, t := range toks[1:] {
+			if t == r.flag {
+				return r.flag, r.what, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+func GenerateDangerCards(events []CommandEvent, existing []Card, now time.Time) []Card {
+	have := map[string]bool{}
+	for _, c := range existing {
+		have[c.ID] = true
+	}
+	out := []Card{}
+	for _, ev := range events {
+		flag, what, ok := dangerFlag(ev.Command)
+		if !ok {
+			continue
+		}
+		id := hash("danger:" + ev.Command)
+		if have[id] {
+			continue
+		}
+		have[id] = true
+		out = append(out, Card{
+			ID:      id,
+			Type:    cards.Comprehension,
+			Prompt:  "⚠ What does " + flag + " do here, and what will it destroy?\n  " + ev.Command,
+			Answer:  what,
+			Hint:    "Describe the effect in a few words",
+			Command: ev.Command,
+			Tags:    unique(append(deriveTags(ev.Command), dangerTag)),
+			Box:     1, NextDue: now, SeenCount: 1, Origins: mergeOrigin(nil, ev.Origin),
+		})
+	}
+	return out
+}
+
+var stopwords = set("the", "and", "that", "with", "from", "their", "them", "this", "even", "also", "each", "every", "into", "without", "byte")
+
+// checkKeywords passes when the answer mentions at least a third of the
+// significant words of the expected description.
+func checkKeywords(want, ans string) bool {
+	ans = strings.ToLower(ans)
+	keys := 0
+	hits := 0
+	for _, w := range strings.Fields(strings.ToLower(want)) {
+		w = strings.Trim(w, ",.;:()")
+		if len(w) < 4 || stopwords[w] {
+			continue
+		}
+		keys++
+		// crude stemming: "deletes" ~ "delete", "destroying" ~ "destroy"
+		stem := w
+		if len(stem) > 5 {
+			stem = stem[:len(stem)-2]
+		}
+		if strings.Contains(ans, stem) {
+			hits++
+		}
+	}
+	need := keys / 3
+	if need < 1 {
+		need = 1
+	}
+	return hits >= need
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// Deck file format. Bump deckFormatVersion on incompatible changes; readers
+// reject decks newer than they understand.
+const (
+	deckFormat        = "memento-deck"
+	deckFormatVersion = 1
+)
+
+type Deck struct {
+	Format      string     `json:"format"`
+	Version     int        `json:"version"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	License     string     `json:"license,omitempty"`
+	Created     time.Time  `json:"created,omitempty"`
+	Generator   string     `json:"generator,omitempty"` // memento build that exported it
+	Cards       []DeckCard `json:"cards"`
+}
+
+// DeckCard is the shareable part of a Card: content only, no scheduling state.
+type DeckCard struct {
+	Type    CardType `json:"type,omitempty"` // default cloze
+	Prompt  string   `json:"prompt"`
+	Answer  string   `json:"answer"`
+	Hint    string   `json:"hint,omitempty"`
+	Command string   `json:"command"`
+	Tags    []string `json:"tags,omitempty"`
+	Notes   string   `json:"notes,omitempty"`
+}
+
+// Starter decks bundled into the binary.
+//
+//go:embed decks/*.json
+var bundledDecks embed.FS
+
+func bundledDeckNames() []string {
+	entries, _ := bundledDecks.ReadDir("decks")
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ParseDeck(b []byte) (Deck, error) {
+	var d Deck
+	if err := json.Unmarshal(b, &d); err != nil {
+		return d, err
+	}
+	if d.Format != deckFormat {
+		return d, fmt.Errorf("not a memento deck (format %q)", d.Format)
+	}
+	if d.Version < 1 || d.Version > deckFormatVersion {
+		return d, fmt.Errorf("unsupported deck version %d (this build reads up to %d)", d.Version, deckFormatVersion)
+	}
+	if d.Name == "" {
+		return d, fmt.Errorf("deck has no name")
+	}
+	for _, dc := range d.Cards {
+		if dc.Type != "" {
+			if _, err := cards.ParseType(string(dc.Type)); err != nil {
+				return d, fmt.Errorf("card %q: %w", dc.Command, err)
+			}
+		}
+	}
+	return d, nil
+}
+
+// readDeck resolves a bundled deck name, a local file or an http(s) URL.
+func readDeck(src string) (Deck, error) {
+	var b []byte
+	var err error
+	switch {
+	case strings.HasPrefix(src, "https://") || strings.HasPrefix(src, "http://"):
+		b, err = fetchURL(src)
+	case fileExists(src):
+		b, err = os.ReadFile(src)
+	default:
+		b, err = bundledDecks.ReadFile(path.Join("decks", src+".json"))
+		if err != nil {
+			return Deck{}, fmt.Errorf("no deck %q (bundled: %s)", src, strings.Join(bundledDeckNames(), ", "))
+		}
+	}
+	if err != nil {
+		return Deck{}, err
+	}
+	d, err := ParseDeck(b)
+	if err != nil {
+		return d, fmt.Errorf("%s: %w", src, err)
+	}
+	return d, nil
+}
+
+func fetchURL(url string) ([]byte, error) { return fetchURLMax(url, 32<<20, 30*time.Second) }
+
+// fetchURLMax GETs url, reading at most max bytes.
+func fetchURLMax(url string, max int64, timeout time.Duration) ([]byte, error) {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, max))
+}
+
+func fileExists(p string) bool {
+	st, err := os.Stat(p)
+	return err == nil && !st.IsDir()
+}
+
+// deckCards turns deck entries into fresh cards tagged with the deck name.
+func deckCards(d Deck, now time.Time) []Card {
+	out := make([]Card, 0, len(d.Cards))
+	for _, dc := range d.Cards {
+		if dc.Command == "" || dc.Answer == "" {
+			continue
+		}
+		hint := dc.Hint
+		if hint == "" {
+			hint = "Type the missing flag/subcommand"
+		}
+		typ := dc.Type
+		if typ == "" {
+			typ = cards.Cloze
+		}
+		out = append(out, Card{
+			ID: hash("deck:" + d.Name + ":" + dc.Command), Type: typ, Prompt: dc.Prompt, Answer: dc.Answer, Hint: hint,
+			Command: dc.Command, Tags: unique(append(append([]string{}, dc.Tags...), "deck/"+d.Name)),
+			Notes: dc.Notes, Box: 1, NextDue: now,
+		})
+	}
+	return out
+}
+
+func ExportDeck(name string, cards []Card, now time.Time) Deck {
+	d := Deck{Format: deckFormat, Version: deckFormatVersion, Name: name, Created: now.UTC(), Generator: buildInfo().String()}
+	for _, c := range cards {
+		tags := []string{}
+		for _, t := range c.Tags {
+			if !strings.HasPrefix(t, "deck/") {
+				tags = append(tags, t)
+			}
+		}
+		d.Cards = append(d.Cards, DeckCard{
+			Type: c.TypeOf(), Prompt: c.Prompt, Answer: c.Answer, Hint: c.Hint, Command: c.Command, Tags: tags, Notes: c.Notes,
+		})
+	}
+	return d
+}
+
+func installDeck(d Deck) error {
+	before, after, err := addCards(deckCards(d, time.Now()))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Installed deck %s: %d new cards. Total: %d\n", d.Name, after-before, after)
+	if d.License != "" {
+		fmt.Printf("License: %s\n", d.License)
+	}
+	return nil
+}
+
+const tutorialDeck = "memento-tutorial"
+
+// installTutorial adds the bundled tutorial deck before the very first review
+// (no review log yet), unless it is already installed.
+func installTutorial() (bool, error) {
+	reviews, err := LoadReviews()
+	if err != nil || len(reviews) > 0 {
+		return false, err
+	}
+	d, err := readDeck(tutorialDeck)
+	if err != nil {
+		return false, err
+	}
+	installed := false
+	err = updateCards(func(cards []Card) []Card {
+		for _, c := range cards {
+			if hasTag(c, "deck/"+tutorialDeck) {
+				return nil
+			}
+		}
+		installed = true
+		return UpsertCards(cards, deckCards(d, time.Now()))
+	})
+	return installed, err
+}
+
+func runDeck(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: memento deck list | install <name|file|url> | export [flags]")
+	}
+	switch args[0] {
+	case "list":
+		for _, n := range bundledDeckNames() {
+			d, err := readDeck(n)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%-16s %3d cards  %s\n", n, len(d.Cards), d.Description)
+		}
+		return nil
+	case "install":
+		fs := flag.NewFlagSet("deck install", flag.ExitOnError)
+		_ = fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: memento deck install <name|file|url>")
+		}
+		d, err := readDeck(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		return installDeck(d)
+	case "export":
+		fs := flag.NewFlagSet("deck export", flag.ExitOnError)
+		tag := fs.String("tag", "", "only export cards with this tag")
+		name := fs.String("name", "", "deck name (default: the tag)")
+		desc := fs.String("description", "", "deck description")
+		author := fs.String("author", "", "deck author")
+		license := fs.String("license", "", "deck license, e.g. CC-BY-4.0")
+		out := fs.String("out", "-", "output file (\"-\" = stdout)")
+		_ = fs.Parse(args[1:])
+		if *name == "" {
+			*name = *tag
+		}
+		if *name == "" {
+			return fmt.Errorf("deck export: --name or --tag is required")
+		}
+		cards, err := LoadCards()
+		if err != nil {
+			return err
+		}
+		if *tag != "" {
+			cards = filterCards(cards, func(c Card) bool { return hasTag(c, *tag) })
+		}
+		d := ExportDeck(*name, cards, time.Now())
+		d.Description, d.Author, d.License = *desc, *author, *license
+		b, err := json.MarshalIndent(d, "", " ")
+		if err != nil {
+			return err
+		}
+		b = append(b, '\n')
+		if *out == "-" {
+			_, err = os.Stdout.Write(b)
+			return err
+		}
+		if err := os.WriteFile(*out, b, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Exported %d cards to %s\n", len(d.Cards), *out)
+		return nil
+	default:
+		return fmt.Errorf("unknown deck command %q", args[0])
+	}
+}
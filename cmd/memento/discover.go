@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// Discover cards come from tldr-pages examples for tools not (yet) in the user's history.
+
+const discoverTag = "discover"
+
+var (
+	tldrPlaceholder = regexp.MustCompile(`\{\{(.*?)\}\}`)
+	tldrPlatforms   = []string{"common", "linux", "osx"}
+	tldrBaseURL     = "https://raw.githubusercontent.com/tldr-pages/tldr/main/pages"
+)
+
+type tldrExample struct {
+	Description string
+	Command     string
+}
+
+// tldrLocalDirs lists page caches used by common tldr clients.
+func tldrLocalDirs() []string {
+	h, _ := os.UserHomeDir()
+	cache := os.Getenv("XDG_CACHE_HOME")
+	if cache == "" {
+		cache = filepath.Join(h, ".cache")
+	}
+	return []string{
+		filepath.Join(cache, "tealdeer", "tldr-pages", "pages"),
+		filepath.Join(cache, "tldr", "pages"),
+		filepath.Join(h, ".tldrc", "tldr", "pages"),
+		filepath.Join(h, ".local", "share", "tldr", "pages"),
+	}
+}
+
+func fetchTldrPage(tool string) (string, error) {
+	for _, d := range tldrLocalDirs() {
+		for _, plat := range tldrPlatforms {
+			if b, err := os.ReadFile(filepath.Join(d, plat, tool+".md")); err == nil {
+				return string(b), nil
+			}
+		}
+	}
+	client := &http.Client{Timeout: 15 * time.Second}
+	for _, plat := range tldrPlatforms {
+		resp, err := client.Get(tldrBaseURL + "/" + plat + "/" + tool + ".md")
+		if err != nil {
+			return "", err
+		}
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode == http.StatusOK {
+			return string(b), nil
+		}
+	}
+	return "", fmt.Errorf("no tldr page found for %q", tool)
+}
+
+func parseTldr(page string) []tldrExample {
+	var out []tldrExample
+	desc := ""
+	s := bufio.NewScanner(strings.NewReader(page))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case strings.HasPrefix(line, "- "):
+			desc = strings.TrimSuffix(strings.TrimPrefix(line, "- "), ":")
+		case strings.HasPrefix(line, "`") && strings.HasSuffix(line, "`") && len(line) > 2:
+			out = append(out, tldrExample{Description: desc, Command: tldrCommand(line[1 : len(line)-1])})
+			desc = ""
+		}
+	}
+	return out
+}
+
+// tldrCommand turns {{path/to/file}} style placeholders into <FILE>.
+func tldrCommand(cmd string) string {
+	return tldrPlaceholder.ReplaceAllStringFunc(cmd, func(m string) string {
+		inner := tldrPlaceholder.FindStringSubmatch(m)[1]
+		if strings.HasPrefix(inner, "-") {
+			return inner // optional flag like {{-v}}
+		}
+		if i := strings.LastIndex(inner, "/"); i >= 0 {
+			inner = inner[i+1:]
+		}
+		inner = strings.Trim(strings.ToUpper(strings.NewReplacer(" ", "_", ".", "_").Replace(inner)), "_")
+		if inner == "" {
+			inner = "ARG"
+		}
+		return "<" + inner + ">"
+	})
+}
+
+func DiscoverCards(tool string, examples []tldrExample, now time.Time) []Card {
+	out := []Card{}
+	for _, ex := range examples {
+		cmd := wsCollapse.ReplaceAllString(strings.TrimSpace(ex.Command), " ")
+		if cmd == "" {
+			continue
+		}
+		prompt, answer, _ := cloze(cmd)
+		if !strings.ContainsAny(strings.ToLower(answer), "abcdefghijklmnopqrstuvwxyz") || strings.Contains(answer, "<") {
+			continue
+		}
+		hint := "Type the missing flag/subcommand"
+		if ex.Description != "" {
+			hint = ex.Description
+		}
+		out = append(out, Card{
+			ID: hash("discover:" + cmd), Type: cards.Cloze, Prompt: prompt, Answer: answer, Hint: hint, Command: cmd,
+			Tags: unique(append(deriveTags(cmd), tool, discoverTag)), Box: 1, NextDue: now,
+		})
+	}
+	return out
+}
+
+func runDiscover(args []string) error {
+	fs := flag.NewFlagSet("discover", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: memento discover <tool>")
+	}
+	tool := fs.Arg(0)
+	page, err := fetchTldrPage(tool)
+	if err != nil {
+		return err
+	}
+	incoming := DiscoverCards(tool, parseTldr(page), time.Now())
+	if len(incoming) == 0 {
+		return fmt.Errorf("no usable examples on the tldr page for %q", tool)
+	}
+	before, after, err := addCards(incoming)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %d discover cards for %s. Total: %d\n", after-before, tool, after)
+	return nil
+}
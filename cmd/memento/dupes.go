@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duplicates: cards of one kind with the same answer whose prompts are nearly
+// the same text, typically left behind by normalizer changes rehash can't see
+// (different commands, same question).
+
+// similarity is 1 for identical strings, falling to 0 with edit distance.
+func similarity(a, b string) float64 {
+	n := max(len([]rune(a)), len([]rune(b)))
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(n)
+}
+
+// dupeGroups clusters cards sharing kind and answer (case-insensitive) when
+// their prompts are at least minSim alike, transitively. Groups come out
+// keeper first (see keeperLess), largest group first.
+func dupeGroups(cards []Card, minSim float64) [][]Card {
+	byAnswer := map[string][]int{}
+	for i, c := range cards {
+		a := strings.ToLower(strings.TrimSpace(c.Answer))
+		if a == "" {
+			continue
+		}
+		k := c.Kind() + "\x00" + a
+		byAnswer[k] = append(byAnswer[k], i)
+	}
+	parent := map[int]int{}
+	var root func(int) int
+	root = func(i int) int {
+		for parent[i] != i {
+			i = parent[i]
+		}
+		return i
+	}
+	for _, idx := range byAnswer {
+		for _, i := range idx {
+			parent[i] = i
+		}
+		for x, i := range idx {
+			for _, j := range idx[x+1:] {
+				if root(i) != root(j) && similarity(strings.ToLower(cards[i].Prompt), strings.ToLower(cards[j].Prompt)) >= minSim {
+					parent[root(j)] = root(i)
+				}
+			}
+		}
+	}
+	groups := map[int][]Card{}
+	for i := range parent {
+		r := root(i)
+		groups[r] = append(groups[r], cards[i])
+	}
+	out := [][]Card{}
+	for _, g := range groups {
+		if len(g) < 2 {
+			continue
+		}
+		sort.Slice(g, func(i, j int) bool { return keeperLess(g[i], g[j]) })
+		out = append(out, g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i]) != len(out[j]) {
+			return len(out[i]) > len(out[j])
+		}
+		return out[i][0].ID < out[j][0].ID
+	})
+	return out
+}
+
+// keeperLess orders the card with the most progress first: higher box, more
+// reviews, reviewed more recently.
+func keeperLess(a, b Card) bool {
+	if a.Box != b.Box {
+		return a.Box > b.Box
+	}
+	if a.TimesSeen != b.TimesSeen {
+		return a.TimesSeen > b.TimesSeen
+	}
+	if !a.LastReviewed.Equal(b.LastReviewed) {
+		return a.LastReviewed.After(b.LastReviewed)
+	}
+	return a.ID < b.ID
+}
+
+// mergeDupes folds others into keep: keep's schedule stands, review and seen
+// counts add up, and the others' answers, origins, tags and notes carry over.
+func mergeDupes(keep Card, others []Card) Card {
+	for _, o := range others {
+		keep.TimesSeen += o.TimesSeen
+		keep.SeenCount += o.SeenCount
+		if o.LastReviewed.After(keep.LastReviewed) {
+			keep.LastReviewed = o.LastReviewed
+		}
+		if !o.Created.IsZero() && (keep.Created.IsZero() || o.Created.Before(keep.Created)) {
+			keep.Created = o.Created
+		}
+		for _, a := range append([]string{o.Answer}, o.AltAnswers...) {
+			if !strings.EqualFold(a, keep.Answer) && !hasFold(keep.AltAnswers, a) {
+				keep.AltAnswers = append(keep.AltAnswers, a)
+			}
+		}
+		for _, og := range o.Origins {
+			keep.Origins = mergeOrigin(keep.Origins, og)
+		}
+		keep.Tags = union(keep.Tags, o.Tags)
+		if o.Notes != "" && !strings.Contains(keep.Notes, o.Notes) {
+			keep.Notes = strings.TrimSpace(keep.Notes + "\n" + o.Notes)
+		}
+		for ph, v := range o.Samples {
+			if _, ok := keep.Samples[ph]; !ok {
+				if keep.Samples == nil {
+					keep.Samples = map[string]string{}
+				}
+				keep.Samples[ph] = v
+			}
+		}
+		keep.Pinned = keep.Pinned || o.Pinned
+		keep.Starred = keep.Starred || o.Starred
+		keep.Suspended = keep.Suspended && o.Suspended
+	}
+	return keep
+}
+
+func hasFold(list []string, s string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func runDupes(args []string) error {
+	fs := flag.NewFlagSet("dupes", flag.ExitOnError)
+	minSim := fs.Float64("similarity", 0.85, "how alike prompts must be (0..1) to count as duplicates")
+	merge := fs.Bool("merge", false, "merge each group, asking which card to keep")
+	yes := fs.Bool("yes", false, "with --merge: keep the card with the most progress without asking")
+	_ = fs.Parse(args)
+	if *minSim < 0 || *minSim > 1 {
+		return fmt.Errorf("--similarity must be between 0 and 1")
+	}
+
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	groups := dupeGroups(cards, *minSim)
+	if len(groups) == 0 {
+		fmt.Println("No duplicates found.")
+		return nil
+	}
+	now := time.Now()
+	a := asker{r: bufio.NewReader(os.Stdin), w: os.Stdout}
+	moved := map[string]string{}
+	merged := map[string]Card{}
+	for gi, g := range groups {
+		fmt.Printf("\n[%d/%d] answer %q\n", gi+1, len(groups), g[0].Answer)
+		for i, c := range g {
+			fmt.Printf("  %d) %s\n", i+1, cardRow(c, now))
+		}
+		if !*merge {
+			continue
+		}
+		keep := 0
+		if !*yes {
+			if !interactive() {
+				return fmt.Errorf("--merge needs a terminal; add --yes to keep the card with the most progress")
+			}
+			choice := strings.ToLower(a.ask("  keep which? [1-"+strconv.Itoa(len(g))+"], [s]kip, [q]uit", "1"))
+			if choice == "q" {
+				break
+			}
+			n, err := strconv.Atoi(choice)
+			if err != nil || n < 1 || n > len(g) {
+				continue
+			}
+			keep = n - 1
+		}
+		others := append(append([]Card{}, g[:keep]...), g[keep+1:]...)
+		k := mergeDupes(g[keep], others)
+		merged[k.ID] = k
+		for _, o := range others {
+			moved[o.ID] = k.ID
+		}
+	}
+	if !*merge {
+		fmt.Printf("\n%d groups; merge them with memento dupes --merge.\n", len(groups))
+		return nil
+	}
+	if len(moved) == 0 {
+		fmt.Println("Nothing merged.")
+		return nil
+	}
+	n, err := saveMerges(fmt.Sprintf("dupes --merge (%d cards)", len(moved)), merged, moved)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Merged %d cards into %d; %d logged reviews moved with them.\n", len(moved), len(merged), n)
+	return nil
+}
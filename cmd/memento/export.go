@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "markdown", "output format: markdown")
+	splitBy := fs.String("split-by", "none", "split output files by: none|tag")
+	out := fs.String("out", "memento-export", "output directory (\"-\" = stdout, only with --split-by none)")
+	tag := fs.String("tag", "", "only export cards with this tag")
+	query := fs.String("query", "", `only export cards matching a query, e.g. "tool:git box:>=4"`)
+	_ = fs.Parse(args)
+	q, err := ParseQuery(*query)
+	if err != nil {
+		return err
+	}
+
+	if *format != "markdown" {
+		return fmt.Errorf("unknown export format %q", *format)
+	}
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	if *tag != "" {
+		cards = filterCards(cards, func(c Card) bool { return hasTag(c, *tag) })
+	}
+	now := time.Now()
+	cards = filterCards(cards, func(c Card) bool { return q.Match(c, now) })
+
+	groups := map[string][]Card{}
+	switch *splitBy {
+	case "none":
+		groups["memento"] = cards
+	case "tag":
+		for _, c := range cards {
+			if len(c.Tags) == 0 {
+				groups["untagged"] = append(groups["untagged"], c)
+			}
+			for _, t := range c.Tags {
+				groups[t] = append(groups[t], c)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown --split-by %q", *splitBy)
+	}
+
+	if *out == "-" {
+		if *splitBy != "none" {
+			return fmt.Errorf("--out - requires --split-by none")
+		}
+		fmt.Print(markdownDeck("memento", cards))
+		return nil
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+	for name, cs := range groups {
+		p := filepath.Join(*out, fileSafe(name)+".md")
+		if err := os.WriteFile(p, []byte(markdownDeck(name, cs)), 0o644); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Exported %d cards to %d file(s) in %s\n", len(cards), len(groups), *out)
+	return nil
+}
+
+func filterCards(cards []Card, keep func(Card) bool) []Card {
+	out := []Card{}
+	for _, c := range cards {
+		if keep(c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func fileSafe(s string) string {
+	return strings.NewReplacer("/", "-", "\\", "-", ":", "-", " ", "_").Replace(s)
+}
+
+// markdownDeck renders cards in a stable, grep-friendly layout (sorted by command).
+func markdownDeck(title string, cards []Card) string {
+	cs := append([]Card{}, cards...)
+	sort.SliceStable(cs, func(i, j int) bool {
+		if cs[i].Command != cs[j].Command {
+			return cs[i].Command < cs[j].Command
+		}
+		return cs[i].ID < cs[j].ID
+	})
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!-- exported by %s -->\n# %s\n", buildInfo(), title)
+	for _, c := range cs {
+		fmt.Fprintf(&b, "\n## `%s`\n\n", c.Prompt)
+		fmt.Fprintf(&b, "- **Answer:** `%s`\n", c.Answer)
+		if c.Hint != "" {
+			fmt.Fprintf(&b, "- **Hint:** %s\n", c.Hint)
+		}
+		fmt.Fprintf(&b, "- **Command:** `%s`\n", c.Command)
+		if len(c.Tags) > 0 {
+			tags := append([]string{}, c.Tags...)
+			sort.Strings(tags)
+			fmt.Fprintf(&b, "- **Tags:** %s\n", strings.Join(tags, ", "))
+		}
+		fmt.Fprintf(&b, "- **Box:** %d\n", c.Box)
+		fmt.Fprintf(&b, "- **ID:** `%s`\n", shortID(c.ID))
+		if c.Notes != "" {
+			fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(c.Notes))
+		}
+	}
+	return b.String()
+}
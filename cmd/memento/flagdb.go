@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"memento/pkg/storage"
+)
+
+// flagTable maps "tool" or "tool sub" to short flag → long flag.
+type flagTable map[string]map[string]string
+
+// Short/long flag pairs for common tools; `memento flags learn` adds more.
+//
+//go:embed flags/flags.json
+var bundledFlags []byte
+
+// longFlags is ingest.long_flags, set by configure: normalize -R to
+// --recursive so both spellings are one card.
+var longFlags = true
+
+var (
+	flagDBOnce sync.Once
+	flagDB     flagTable
+)
+
+func learnedFlagsPath() (string, error) {
+	p, err := storage.CardsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "flags.json"), nil
+}
+
+func loadLearnedFlags() (flagTable, error) {
+	p, err := learnedFlagsPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return flagTable{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t := flagTable{}
+	return t, json.Unmarshal(b, &t)
+}
+
+// flags is the bundled table, then the updated knowledge base (kb.go), then
+// learned entries on top.
+func flags() flagTable {
+	flagDBOnce.Do(func() {
+		flagDB = flagTable{}
+		_ = json.Unmarshal(bundledFlags, &flagDB)
+		kb, err := loadKB()
+		if err != nil {
+			logger.Warn("flags: ignoring knowledge base", "err", err)
+		}
+		mergeTable(flagDB, kb.Flags)
+		learned, err := loadLearnedFlags()
+		if err != nil {
+			logger.Warn("flags: ignoring learned table", "err", err)
+		}
+		mergeTable(flagDB, learned)
+	})
+	return flagDB
+}
+
+// toolKeys are the table keys for a command: "tool", then "tool sub" when a
+// plain word follows the tool (normalized git commands put flags first).
+func toolKeys(words []string) []string {
+	if len(words) == 0 {
+		return nil
+	}
+	keys := []string{words[0]}
+	for _, w := range words[1:] {
+		if strings.HasPrefix(w, "-") || placeholderRe.MatchString(w) {
+			continue
+		}
+		return append(keys, words[0]+" "+w)
+	}
+	return keys
+}
+
+// flagsFor merges the tool's table with its subcommand's (words: the command).
+func flagsFor(words []string) map[string]string {
+	db := flags()
+	out := map[string]string{}
+	for _, k := range toolKeys(words) {
+		for s, l := range db[k] {
+			out[s] = l
+		}
+	}
+	return out
+}
+
+// Flag descriptions for common tools, keyed like flags.json by the spelling
+// normalizeCommand leaves: the long form where there is one.
+//
+//go:embed flags/descriptions.json
+var bundledDescriptions []byte
+
+var (
+	flagDescOnce sync.Once
+	flagDesc     flagTable
+)
+
+func descriptions() flagTable {
+	flagDescOnce.Do(func() {
+		flagDesc = flagTable{}
+		_ = json.Unmarshal(bundledDescriptions, &flagDesc)
+		kb, err := loadKB()
+		if err != nil {
+			logger.Warn("flags: ignoring knowledge base", "err", err)
+		}
+		mergeTable(flagDesc, kb.Descriptions)
+	})
+	return flagDesc
+}
+
+// describeFlag explains flag f of the command words, in either spelling;
+// "" when the bundled table doesn't know it.
+func describeFlag(words []string, f string) string {
+	if !strings.HasPrefix(f, "-") {
+		return ""
+	}
+	db := descriptions()
+	keys := toolKeys(words)
+	names := append([]string{f}, flagEquivalents(words, f)...)
+	for i := len(keys) - 1; i >= 0; i-- { // subcommand first
+		for _, n := range names {
+			if d := db[keys[i]][n]; d != "" {
+				return d
+			}
+		}
+	}
+	return ""
+}
+
+// flagEquivalents lists the other spellings of f for the command's tool:
+// its long form, or every short form of a long flag.
+func flagEquivalents(words []string, f string) []string {
+	if !strings.HasPrefix(f, "-") {
+		return nil
+	}
+	out := []string{}
+	for s, l := range flagsFor(words) {
+		switch f {
+		case s:
+			out = append(out, l)
+		case l:
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// expandShortFlags rewrites standalone short flags to their long form,
+// looking tools up per pipeline/list segment.
+func expandShortFlags(toks []string) []string {
+	start := 0
+	for i, t := range toks {
+		switch t {
+		case "|", "||", "&&", ";":
+			start = i + 1
+			continue
+		}
+		if i == start || len(t) != 2 || t[0] != '-' {
+			continue
+		}
+		if l, ok := flagsFor(toks[start:])[t]; ok {
+			toks[i] = l
+		}
+	}
+	return toks
+}
+
+var helpFlagRe = regexp.MustCompile(`(?m)^\s*(-[A-Za-z0-9]),\s*(--[a-z][a-z0-9-]*)`)
+
+// learnFlags scrapes `tool [sub] --help` for "-x, --long" pairs.
+func learnFlags(words []string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, words[0], append(words[1:], "--help")...).CombinedOutput()
+	if len(out) == 0 && err != nil {
+		return nil, err
+	}
+	m := map[string]string{}
+	for _, sm := range helpFlagRe.FindAllStringSubmatch(string(out), -1) {
+		m[sm[1]] = sm[2]
+	}
+	return m, nil
+}
+
+func runFlags(args []string) error {
+	fs := flag.NewFlagSet("flags", flag.ExitOnError)
+	_ = fs.Parse(args)
+	rest := fs.Args()
+	if len(rest) > 0 && rest[0] == "learn" {
+		if len(rest) < 2 || len(rest) > 3 {
+			return fmt.Errorf("usage: memento flags learn <tool> [subcommand]")
+		}
+		words := rest[1:]
+		m, err := learnFlags(words)
+		if err != nil {
+			return fmt.Errorf("%s --help: %w", strings.Join(words, " "), err)
+		}
+		if len(m) == 0 {
+			return fmt.Errorf("no \"-x, --long\" pairs in %s --help", strings.Join(words, " "))
+		}
+		learned, err := loadLearnedFlags()
+		if err != nil {
+			return err
+		}
+		learned[strings.Join(words, " ")] = m
+		b, err := json.MarshalIndent(learned, "", " ")
+		if err != nil {
+			return err
+		}
+		p, err := learnedFlagsPath()
+		if err != nil {
+			return err
+		}
+		if err := storage.WriteFileAtomic(p, b, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Learned %d flag pairs for %s (saved to %s).\n", len(m), strings.Join(words, " "), storage.TildePath(p))
+		if longFlags {
+			fmt.Println("Cards ingested before this use the old spelling; run memento rehash to merge them.")
+		}
+		return nil
+	}
+	if len(rest) == 0 {
+		db := flags()
+		tools := make([]string, 0, len(db))
+		for k := range db {
+			tools = append(tools, k)
+		}
+		sort.Strings(tools)
+		for _, k := range tools {
+			fmt.Printf("%-18s %d flags\n", k, len(db[k]))
+		}
+		return nil
+	}
+	m := flagsFor(rest)
+	if len(m) == 0 {
+		return fmt.Errorf("no flags known for %s; try memento flags learn %s", strings.Join(rest, " "), strings.Join(rest, " "))
+	}
+	shorts := make([]string, 0, len(m))
+	for s := range m {
+		shorts = append(shorts, s)
+	}
+	sort.Strings(shorts)
+	for _, s := range shorts {
+		fmt.Printf("%s  %-22s %s\n", s, m[s], describeFlag(rest, m[s]))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"memento/pkg/storage"
+)
+
+// Hooks are user executables in <config dir>/hooks/, named after the event:
+//
+//	pre-ingest   stdin: {"sources": [...]}; a non-zero exit aborts the ingest
+//	post-ingest  stdin: JSON array of the cards the ingest created
+//	post-review  stdin: the session summary (ReviewSession)
+func hookPath(name string) (string, bool) {
+	d, err := configDir()
+	if err != nil {
+		return "", false
+	}
+	p := filepath.Join(d, "hooks", name)
+	st, err := os.Stat(p)
+	if err != nil || st.IsDir() || st.Mode()&0o111 == 0 {
+		return "", false
+	}
+	return p, true
+}
+
+// runHook feeds payload as JSON to the named hook, if installed. The hook
+// shares our stdout/stderr and gets MEMENTO_HOOK and MEMENTO_DATA_DIR.
+func runHook(name string, payload any) error {
+	p, ok := hookPath(name)
+	if !ok {
+		return nil
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	data, _ := storage.DataDir()
+	cmd := exec.Command(p)
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), "MEMENTO_HOOK="+name, "MEMENTO_DATA_DIR="+data)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook: %w", name, err)
+	}
+	return nil
+}
+
+// warnHook runs a post-* hook; its failure is reported but never fatal.
+func warnHook(name string, payload any) {
+	if err := runHook(name, payload); err != nil {
+		fmt.Fprintln(os.Stderr, "warning:", err)
+	}
+}
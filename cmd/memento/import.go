@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "anki", "input format: anki")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: memento import --format anki <deck.apkg>")
+	}
+
+	var incoming []Card
+	var err error
+	switch *format {
+	case "anki":
+		incoming, err = ImportAnki(fs.Arg(0), time.Now())
+	default:
+		return fmt.Errorf("unknown import format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	before, after, err := addCards(incoming)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d cards (%d new). Total: %d\n", len(incoming), after-before, after)
+	return nil
+}
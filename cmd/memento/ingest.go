@@ -0,0 +1,816 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"memento/pkg/cards"
+	"memento/pkg/ingest"
+)
+
+type CommandEvent struct {
+	When    time.Time // most recent occurrence
+	First   time.Time // earliest occurrence
+	Command string
+	Example string // most recent scrubbed raw form of Command
+	Origin  Origin
+}
+
+var (
+	pathLike = regexp.MustCompile(`(~|\.{1,2}|/)[\w@./\-+:%]+`)
+	urlRe    = regexp.MustCompile(`https?://\S+`)
+	uuidRe   = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	shaRe    = regexp.MustCompile(`\b[0-9a-f]{7,40}\b`)
+	ipRe     = regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}\b`)
+	bigNumRe = regexp.MustCompile(`\b\d{3,}\b`)
+	anyNumRe = regexp.MustCompile(`\b\d+\b`)
+	dottedRe = regexp.MustCompile(`\b[A-Za-z0-9][\w-]*(\.[\w-]+)+\b`) // hosts, file names
+
+	// set from config (ingest.history_files, ingest.masking)
+	historyFiles []string
+	maskingLevel = "standard"
+	dropSecrets  bool
+	varAssign    = regexp.MustCompile(`\b[A-Za-z_][A-Za-z0-9_]*=([^ \t]+)`)
+	wsCollapse   = regexp.MustCompile(`\s+`)
+)
+
+var valueFlags = map[string]string{
+	"-o": "<PATH>", "--output": "<PATH>", "-i": "<PATH>", "--input": "<PATH>",
+	"-f": "<FILE>", "--file": "<FILE>", "--namespace": "<NS>", "-n": "<NS>",
+	"--context": "<CTX>", "-r": "<REPO>", "--repo": "<REPO>",
+	"--kubeconfig": "<PATH>", "--config": "<PATH>",
+}
+
+var placeholderRe = regexp.MustCompile(`^<[A-Z]+>$`)
+
+// flagValue splits a flag+value answer ("--namespace <NS>") into its parts.
+func flagValue(answer string) (name, ph string, ok bool) {
+	name, ph, ok = strings.Cut(answer, " ")
+	if !ok || !strings.HasPrefix(name, "-") || !placeholderRe.MatchString(ph) {
+		return answer, "", false
+	}
+	return name, ph, true
+}
+
+// blankAt masks words[i]. A value-taking flag keeps its placeholder in the
+// prompt ("_____ <NS>") and takes it into the answer, so the card asks which
+// flag takes that value.
+func blankAt(words []string, i int) (prompt, answer string) {
+	masked := append([]string{}, words...)
+	masked[i] = "_____"
+	answer = words[i]
+	if valueFlags[answer] != "" && i+1 < len(words) && placeholderRe.MatchString(words[i+1]) {
+		answer += " " + words[i+1]
+	}
+	return strings.Join(masked, " "), answer
+}
+
+// eventSet dedups commands by normalized form, tracking first/last occurrence.
+type eventSet map[string]CommandEvent
+
+func (es eventSet) add(raw string, when time.Time, origin Origin) {
+	raw = scrub(raw)
+	if isIgnorable(raw) {
+		if isSensitive(raw) {
+			logger.Debug("ingest skip", "reason", "sensitive", "file", origin.File)
+		}
+		return
+	}
+	canon := normalizeCommand(raw)
+
+	prev, ok := es[canon]
+	if !ok {
+		es[canon] = CommandEvent{When: when, First: when, Command: canon, Example: raw, Origin: origin}
+		return
+	}
+	if when.After(prev.When) {
+		prev.When = when
+		prev.Origin = origin
+		prev.Example = raw
+	}
+	if when.Before(prev.First) {
+		prev.First = when
+	}
+	es[canon] = prev
+}
+
+func (es eventSet) events() []CommandEvent {
+	events := make([]CommandEvent, 0, len(es))
+	for _, ev := range es {
+		ev.Origin.FirstSeen, ev.Origin.LastSeen = ev.First, ev.When
+		events = append(events, ev)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].When.After(events[j].When) })
+	return events
+}
+
+func ParseHistory(srcs []ingest.Source, w ingest.Window) []CommandEvent {
+	uniq := eventSet{}
+	ingest.Walk(srcs, func(raw string, when time.Time, origin Origin) {
+		ingestMeter.line(origin.File, raw)
+		if w.Contains(when) {
+			uniq.add(raw, when, origin)
+		}
+	})
+	return uniq.events()
+}
+
+// defaultSources is every known history file (shell sniffed from content)
+// plus the capture log.
+func defaultSources() []ingest.Source {
+	out := []ingest.Source{}
+	files := historyFiles
+	if len(files) == 0 {
+		files = ingest.GuessHistoryFiles()
+	}
+	for _, p := range files {
+		out = append(out, ingest.Source{Path: p, Shell: ingest.SniffShell(p)})
+	}
+	if cp, err := capturePath(); err == nil {
+		out = append(out, ingest.Source{Path: cp, Shell: "capture"})
+	}
+	return out
+}
+
+// Scrub obvious secrets and emails.
+var (
+	emailRe   = regexp.MustCompile(`\b[\w._%+-]+@[\w.-]+\.[A-Za-z]{2,}\b`)
+	hexRe     = regexp.MustCompile(`\b[0-9a-fA-F]{32,}\b`)
+	tokenRe   = regexp.MustCompile(`(?i)(AWS|SECRET|TOKEN|KEY|PASSWORD|PASS|PWD)=\S+`)
+	quoteBlob = regexp.MustCompile(`'[^']+'|"[^"]+"`)
+)
+
+// scrub redacts secrets; with scrub.drop_secrets it blanks the whole command.
+func scrub(s string) string {
+	s, fired := scrubReport(s)
+	if len(fired) > 0 { // rule IDs only: the line itself may still hold a secret
+		logger.Debug("scrub", "rules", fired, "dropped", dropSecrets)
+	}
+	if dropSecrets && len(fired) > 0 {
+		return ""
+	}
+	return s
+}
+
+// scrubReport scrubs s and names the rules that redacted something.
+func scrubReport(s string) (string, []string) {
+	var fired []string
+	for _, r := range secretRules {
+		var hit bool
+		if s, hit = r.apply(s); hit {
+			fired = append(fired, r.ID)
+		}
+	}
+	for _, g := range []struct {
+		id   string
+		re   *regexp.Regexp
+		repl string
+	}{
+		{"generic-assignment", tokenRe, "$1=***"},
+		{"email", emailRe, "***@***"},
+		{"long-hex", hexRe, "<HEX>"},
+	} {
+		if out := g.re.ReplaceAllString(s, g.repl); out != s {
+			s = out
+			fired = append(fired, g.id)
+		}
+	}
+	return s, fired
+}
+
+func isIgnorable(s string) bool {
+	if strings.HasPrefix(s, "#") {
+		return true
+	}
+	if strings.HasPrefix(s, "cd ") {
+		return true
+	}
+	if strings.HasPrefix(s, "ls") {
+		return true
+	}
+	if len(strings.Fields(s)) == 0 {
+		return true
+	}
+	return isSensitive(s)
+}
+
+// isTricky uses the ingest.rules.tricky expression when configured.
+func isTricky(cmd string) bool {
+	if trickyRule != nil {
+		if v, ok := runRule("tricky", trickyRule, cmd); ok {
+			return v.(bool)
+		}
+	}
+	return builtinTricky(cmd)
+}
+
+// Heuristic: mark as tricky if it's long, has pipes, multiple flags, or risky flags.
+func builtinTricky(cmd string) bool {
+	flags := strings.Count(cmd, " -") + strings.Count(cmd, " --")
+	return len(cmd) > 40 || strings.Contains(cmd, "|") || strings.Contains(cmd, "&&") || flags >= 2 ||
+		strings.Contains(cmd, "-rf") || strings.Contains(cmd, "--force")
+}
+
+func GenerateCards(events []CommandEvent, existing []Card) []Card {
+	idx := map[string]*Card{}
+	for i := range existing {
+		idx[existing[i].ID] = &existing[i]
+	}
+
+	out := []Card{}
+	seenIDs := make(map[string]bool)
+
+	for _, ev := range events {
+		if !isTricky(ev.Command) {
+			logger.Debug("ingest skip", "reason", "not tricky", "cmd", logCmd(ev.Command))
+			continue
+		}
+
+		canon := normalizeCommand(ev.Command)
+		id := hash(canon)
+
+		if seenIDs[id] {
+			continue
+		}
+		if c, ok := idx[id]; ok {
+			c.SeenCount++
+			c.Origins = mergeOrigin(c.Origins, ev.Origin)
+			c.Example = exampleOf(canon, ev.Example)
+			c.Samples = cardSamples(c.Example)
+			c.Tags = unique(append(c.Tags, projectTags(ev.Origin)...))
+			continue
+		}
+
+		c := newCard(canon, ev.Origin, time.Now())
+		c.Example = exampleOf(canon, ev.Example)
+		c.Samples = cardSamples(c.Example)
+		logger.Info("card created", "id", shortID(id), "cmd", logCmd(canon), "host", ev.Origin.Host, "file", ev.Origin.File)
+		out = append(out, c)
+		seenIDs[id] = true
+		ingestMeter.found(len(out))
+	}
+	return out
+}
+
+// newCard builds a fresh box-1 card from an already normalized command.
+func newCard(canon string, origin Origin, now time.Time) Card {
+	prompt, answer, hint := cloze(canon)
+	return Card{
+		ID: hash(canon), Type: cards.Cloze, Prompt: prompt, Answer: answer, Hint: hint, Command: canon,
+		Tags: unique(append(deriveTags(canon), projectTags(origin)...)), Box: 1, NextDue: now, SeenCount: 1,
+		Origins: mergeOrigin(nil, origin),
+	}
+}
+
+// exampleOf keeps a concrete run only when masking actually changed something.
+func exampleOf(canon, raw string) string {
+	if raw == canon {
+		return ""
+	}
+	return raw
+}
+
+// exampleSamples pairs placeholders with the tokens a concrete example used
+// for them: the token after a value flag, or one that masks to a single
+// placeholder by itself. Redacted and quoted tokens are left out.
+func exampleSamples(example string) (phs, vals []string) {
+	toks := strings.Fields(example)
+	for i := 1; i < len(toks); i++ {
+		t := toks[i]
+		if strings.HasPrefix(t, "-") || strings.ContainsAny(t, "\"'<>*`$") {
+			continue
+		}
+		ph := valueFlags[toks[i-1]]
+		if m := normalizeCommand(t); ph == "" && placeholderRe.MatchString(m) {
+			ph = m
+		}
+		if ph != "" {
+			phs, vals = append(phs, ph), append(vals, t)
+		}
+	}
+	return phs, vals
+}
+
+// cardSamples keeps the first example value of each placeholder (Card.Samples).
+func cardSamples(example string) map[string]string {
+	phs, vals := exampleSamples(example)
+	if len(phs) == 0 {
+		return nil
+	}
+	out := map[string]string{}
+	for i, ph := range phs {
+		if _, ok := out[ph]; !ok {
+			out[ph] = vals[i]
+		}
+	}
+	return out
+}
+
+func projectTags(o Origin) []string {
+	if o.Project == "" {
+		return nil
+	}
+	return []string{"project/" + o.Project}
+}
+
+func deriveTags(cmd string) []string {
+	parts := strings.Fields(cmd)
+	if len(parts) == 0 {
+		return nil
+	}
+	cmdName := parts[0]
+	tags := []string{cmdName}
+	for _, t := range []string{"git", "kubectl", "ffmpeg", "docker", "grep", "awk", "sed"} {
+		if strings.HasPrefix(cmd, t+" ") {
+			tags = append(tags, t)
+		}
+	}
+	return unique(tags)
+}
+func unique(ss []string) []string {
+	m := map[string]struct{}{}
+	out := []string{}
+	for _, s := range ss {
+		if _, ok := m[s]; !ok {
+			m[s] = struct{}{}
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func hash(s string) string { h := sha1.Sum([]byte(s)); return hex.EncodeToString(h[:]) }
+
+func normalizeCommand(s string) string {
+	if r, ok := pluginNormalize(s); ok {
+		return r.Masked
+	}
+	// strip/standardize quotes first
+	if masks("minimal") {
+		s = quoteBlob.ReplaceAllString(s, "<STR>")
+	}
+
+	// mask volatile atoms
+	hosts := []string{}
+	if masks("minimal") {
+		s = urlRe.ReplaceAllStringFunc(s, func(u string) string {
+			if maskingLevel == "domain" {
+				hosts = append(hosts, urlHost(u))
+			}
+			return "<URL>"
+		})
+		s = emailRe.ReplaceAllString(s, "***@***")
+		s = uuidRe.ReplaceAllString(s, "<UUID>")
+		s = shaRe.ReplaceAllString(s, "<SHA>")
+		s = ipRe.ReplaceAllString(s, "<IP>")
+		s = varAssign.ReplaceAllString(s, "${VAR}=<VAL>")
+	}
+	if masks("domain") {
+		s = pathLike.ReplaceAllString(s, "<PATH>")
+	}
+	if masks("paranoid") {
+		s = dottedRe.ReplaceAllString(s, "<NAME>")
+		s = anyNumRe.ReplaceAllString(s, "<NUM>")
+	} else if masks("domain") {
+		s = bigNumRe.ReplaceAllString(s, "<NUM>")
+	}
+	for _, h := range hosts { // put back after the path pass, which would eat "//host/"
+		s = strings.Replace(s, "<URL>", h, 1)
+	}
+
+	// token-level pass to replace values after known flags
+	toks := strings.Fields(s)
+	if longFlags {
+		toks = expandShortFlags(toks)
+	}
+	for i := 0; i < len(toks) && masks("minimal"); i++ {
+		if ph, ok := valueFlags[toks[i]]; ok && i+1 < len(toks) {
+			// don't stomp other flags
+			if !strings.HasPrefix(toks[i+1], "-") {
+				toks[i+1] = ph
+			}
+		}
+	}
+
+	// optional: sort standalone long flags for stability (mostly safe)
+	toks = stableFlagOrder(toks)
+
+	// rebuild & collapse whitespace
+	out := strings.Join(toks, " ")
+	out = wsCollapse.ReplaceAllString(out, " ")
+	return strings.TrimSpace(out)
+}
+
+// maskingLevels are the ingest.masking tiers, least masking first. Each level
+// runs every pass of the levels before it: minimal masks quoted strings, URLs,
+// IDs, IPs, assignments and flag values; domain adds paths and long numbers
+// but keeps URL hosts; standard masks URLs whole; paranoid also masks every
+// number and dotted name (hosts, file names).
+var maskingLevels = []string{"off", "minimal", "domain", "standard", "paranoid"}
+
+// masks reports whether the configured level runs the passes of level.
+func masks(level string) bool {
+	return slices.Index(maskingLevels, maskingLevel) >= slices.Index(maskingLevels, level)
+}
+
+// urlHost keeps a URL's scheme and host and masks its path and query
+// (masking "domain"), so https://api.github.com/repos/… stays recognisable.
+func urlHost(u string) string {
+	p, err := url.Parse(u)
+	if err != nil || p.Host == "" {
+		return "<URL>"
+	}
+	out := p.Scheme + "://" + ipRe.ReplaceAllString(p.Host, "<IP>")
+	if (p.Path == "" || p.Path == "/") && p.RawQuery == "" && p.Fragment == "" {
+		return out + p.Path
+	}
+	return out + "/<PATH>"
+}
+
+func stableFlagOrder(toks []string) []string {
+	// move --long-flags that don’t have attached values into a stable order
+	flags, rest := []string{}, []string{}
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if strings.HasPrefix(t, "--") {
+			// if next token is a value (not a flag), keep pair in rest
+			if i+1 < len(toks) && !strings.HasPrefix(toks[i+1], "-") && valueFlags[t] != "" {
+				rest = append(rest, t, toks[i+1])
+				i++
+			} else {
+				flags = append(flags, t)
+			}
+		} else {
+			rest = append(rest, t)
+		}
+	}
+	sort.Strings(flags)
+	return append(append([]string{}, rest[0:1]...), append(flags, rest[1:]...)...)
+}
+
+func isBadAnswerToken(w string) bool {
+	if w == "" {
+		return true
+	}
+	if strings.Contains(w, "<") && strings.Contains(w, ">") {
+		return true
+	} // placeholders
+	if strings.Contains(w, "/") || strings.HasPrefix(w, "~") || strings.HasPrefix(w, ".") {
+		return true
+	}
+	if urlRe.MatchString(w) || pathLike.MatchString(w) || shaRe.MatchString(w) || uuidRe.MatchString(w) {
+		return true
+	}
+	if bigNumRe.MatchString(w) {
+		return true
+	}
+	return false
+}
+
+func preferSubcommands(cmdName string) map[string]bool {
+	switch cmdName {
+	case "git":
+		return set("rebase", "cherry-pick", "stash", "reset", "restore", "revert", "checkout", "commit", "fetch", "merge", "push", "pull")
+	case "kubectl":
+		return set("get", "describe", "apply", "delete", "logs", "exec", "rollout", "scale", "port-forward", "top")
+	case "ffmpeg":
+		return set() // mostly flags
+	default:
+		return set()
+	}
+}
+
+func set(ss ...string) map[string]bool {
+	m := map[string]bool{}
+	for _, s := range ss {
+		m[s] = true
+	}
+	return m
+}
+
+func cloze(cmd string) (prompt, answer, hint string) {
+	if p, a, h, ok := pluginCloze(cmd); ok {
+		return p, a, h
+	}
+	if clozeRule != nil {
+		if v, ok := runRule("cloze", clozeRule, cmd); ok {
+			words := strings.Fields(cmd)
+			for i, w := range words {
+				if w == v.(string) && w != "" {
+					prompt, answer := blankAt(words, i)
+					return prompt, answer, genericHint
+				}
+			}
+		}
+	}
+	return heuristicCloze(cmd)
+}
+
+func heuristicCloze(cmd string) (prompt, answer, hint string) {
+	words := strings.Fields(cmd)
+	if len(words) == 0 {
+		return "", "", ""
+	}
+
+	candidates := []int{}
+	// 1) explicit “good” tokens
+	good := preferSubcommands(words[0])
+	for i := 1; i < len(words); i++ {
+		if good[words[i]] {
+			candidates = append(candidates, i)
+		}
+	}
+	// 2) long flags
+	for i := 0; i < len(words); i++ {
+		if strings.HasPrefix(words[i], "--") {
+			candidates = append(candidates, i)
+		}
+	}
+	// 3) short flags
+	for i := 0; i < len(words); i++ {
+		if strings.HasPrefix(words[i], "-") && !strings.HasPrefix(words[i], "--") {
+			candidates = append(candidates, i)
+		}
+	}
+	// 4) fallback: the first non-dynamic non-command token
+	if len(candidates) == 0 {
+		for i := 1; i < len(words); i++ {
+			if !isBadAnswerToken(words[i]) {
+				candidates = append(candidates, i)
+				break
+			}
+		}
+	}
+
+	// pick first candidate that isn’t junk
+	idx := -1
+	for _, i := range candidates {
+		if !isBadAnswerToken(words[i]) {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		idx = 0
+	} // final fallback (rare)
+
+	prompt, answer = blankAt(words, idx)
+	hint = genericHint
+	return
+}
+
+func runIngest(args []string) error {
+	fs := flag.NewFlagSet("ingest", flag.ExitOnError)
+	audit := fs.Bool("audit-scrub", false, "also print which history lines were redacted, and by which rule")
+	since := fs.String("since", "", "only commands newer than this (30d, 2w, 6mo, 1y, 2024-01-31)")
+	between := fs.String("between", "", "only commands within FROM..TO dates, e.g. 2024-01-01..2024-06-30")
+	var files stringList
+	fs.Var(&files, "file", "history file to read instead of the defaults (repeatable)")
+	shell := fs.String("shell", "", "force the parser for --file: zsh|bash|fish (default: sniff)")
+	quiet := fs.Bool("quiet", false, "no progress bar and no summary, only errors (for cron)")
+	only := fs.String("only", "", "comma-separated tools to make cards for, e.g. git,kubectl (default: all)")
+	exclude := fs.String("exclude", "", "comma-separated tools to leave out, e.g. docker")
+	_ = fs.Parse(args)
+	onlyTools, excludeTools := splitList(*only), splitList(*exclude)
+
+	srcs := defaultSources()
+	if len(files) > 0 {
+		srcs = nil
+		for _, f := range files {
+			sh := *shell
+			if sh == "" {
+				sh = ingest.SniffShell(f)
+			}
+			srcs = append(srcs, ingest.Source{Path: f, Shell: sh})
+		}
+	} else if *shell != "" {
+		return fmt.Errorf("--shell only applies together with --file")
+	}
+
+	w, err := ingestWindow(*since, *between, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := runHook("pre-ingest", map[string]any{"sources": srcs}); err != nil {
+		return err
+	}
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	known := map[string]bool{}
+	for _, c := range cards {
+		known[c.ID] = true
+	}
+	st, err := loadStaging()
+	if err != nil {
+		return err
+	}
+	out := io.Writer(os.Stdout)
+	if *quiet {
+		out = io.Discard
+	} else if term.IsTerminal(int(os.Stderr.Fd())) {
+		ingestMeter = newMeter(os.Stderr, srcs)
+	}
+	before := cloneDeck(cards)
+	newCards, staged, rejected := generateApproved(srcs, w, cards, st, time.Now())
+	ingestMeter.done()
+	newCards, capped := pickTools(newCards, cards, onlyTools, excludeTools)
+	staged = slices.DeleteFunc(staged, func(s StagedCard) bool { return !toolAllowed(toolOf(s.Card), onlyTools, excludeTools) })
+	logger.Info("ingest", "sources", len(srcs), "new", len(newCards), "staged", len(staged), "rejected", len(rejected), "capped", len(capped),
+		"since", *since, "between", *between, "only", *only, "exclude", *exclude)
+	total, err := saveIngest(before, cards, newCards)
+	if err != nil {
+		return err
+	}
+	if len(staged)+len(rejected) > 0 {
+		st.stage(staged, rejected)
+		if err := saveStaging(st); err != nil {
+			return err
+		}
+	}
+	if created := filterCards(newCards, func(c Card) bool { return !known[c.ID] }); len(created) > 0 {
+		warnHook("post-ingest", created)
+	}
+	if !w.Open() {
+		fmt.Fprintln(out, "Time window set: commands without timestamps (plain bash history) were skipped.")
+	}
+	if len(newCards) > 0 {
+		fmt.Fprintf(out, "Ingested %d new cards. Total: %d\n", len(newCards), total)
+	} else if len(staged) == 0 && len(capped) == 0 {
+		fmt.Fprintln(out, "No new tricky commands found. You're a wizard.")
+	}
+	if len(staged) > 0 {
+		fmt.Fprintf(out, "%d borderline cards staged; accept or reject them with memento triage.\n", len(staged))
+	}
+	if len(rejected) > 0 {
+		fmt.Fprintf(out, "%d junk cards rejected (reasons in the log).\n", len(rejected))
+	}
+	if len(capped) > 0 {
+		tools := slices.Sorted(maps.Keys(capped))
+		for i, t := range tools {
+			tools[i] = fmt.Sprintf("%s %d", t, capped[t])
+		}
+		fmt.Fprintf(out, "Left out at ingest.max_per_tool: %s.\n", strings.Join(tools, ", "))
+	}
+	if *audit {
+		printAudit(os.Stdout, AuditHistory(srcs), nil)
+	}
+	return nil
+}
+
+// seenUpdate is what an ingest did to a card that was already in the deck.
+type seenUpdate struct {
+	seen     int
+	origins  []Origin
+	tags     []string
+	example  string
+	samples  map[string]string
+	examples bool // example and samples changed
+}
+
+// cloneDeck copies deck deep enough that the generators' in-place updates
+// to tags, origins and samples don't show through.
+func cloneDeck(deck []Card) []Card {
+	out := slices.Clone(deck)
+	for i := range out {
+		out[i].Tags = slices.Clone(out[i].Tags)
+		out[i].Origins = slices.Clone(out[i].Origins)
+		out[i].Samples = maps.Clone(out[i].Samples)
+	}
+	return out
+}
+
+// seenUpdates diffs the deck before and after the generators ran on it.
+func seenUpdates(before, after []Card) map[string]seenUpdate {
+	ups := map[string]seenUpdate{}
+	for i, b := range before {
+		a := after[i]
+		u := seenUpdate{seen: a.SeenCount - b.SeenCount, example: a.Example, samples: a.Samples}
+		u.examples = a.Example != b.Example || !maps.Equal(a.Samples, b.Samples)
+		for _, o := range a.Origins {
+			if !slices.Contains(b.Origins, o) {
+				u.origins = append(u.origins, o)
+			}
+		}
+		for _, t := range a.Tags {
+			if !slices.Contains(b.Tags, t) {
+				u.tags = append(u.tags, t)
+			}
+		}
+		if u.seen != 0 || u.examples || len(u.origins)+len(u.tags) > 0 {
+			ups[a.ID] = u
+		}
+	}
+	return ups
+}
+
+// saveIngest replays the generators' updates to existing cards onto the deck
+// as it is now, in case a review saved meanwhile, and adds created.
+func saveIngest(before, after, created []Card) (total int, err error) {
+	ups := seenUpdates(before, after)
+	err = updateCards(func(cards []Card) []Card {
+		for i := range cards {
+			u, ok := ups[cards[i].ID]
+			if !ok {
+				continue
+			}
+			c := &cards[i]
+			c.SeenCount += u.seen
+			for _, o := range u.origins {
+				c.Origins = mergeOrigin(c.Origins, o)
+			}
+			c.Tags = unique(append(c.Tags, u.tags...))
+			if u.examples {
+				c.Example, c.Samples = u.example, u.samples
+			}
+		}
+		cards = UpsertCards(cards, created)
+		total = len(cards)
+		return cards
+	})
+	return total, err
+}
+
+// maxPerTool is ingest.max_per_tool, set by configure.
+var maxPerTool map[string]int
+
+// toolCap is the most cards tool may hold in the deck (0 = no cap).
+func toolCap(tool string) int {
+	if n, ok := maxPerTool[tool]; ok {
+		return n
+	}
+	return maxPerTool["*"]
+}
+
+// toolAllowed applies ingest --only and --exclude.
+func toolAllowed(tool string, only, exclude []string) bool {
+	return (len(only) == 0 || contains(only, tool)) && !contains(exclude, tool)
+}
+
+// pickTools keeps the new cards --only/--exclude allow, and of those as many
+// per tool as ingest.max_per_tool leaves room for next to the deck's cards,
+// most-run commands first. Cards already in the deck always pass. Whatever
+// is left out isn't tombstoned: a later ingest can still add it.
+func pickTools(created, deck []Card, only, exclude []string) (kept []Card, capped map[string]int) {
+	have, known := map[string]int{}, map[string]bool{}
+	for _, c := range deck {
+		have[toolOf(c)]++
+		known[c.ID] = true
+	}
+	byUse := slices.Clone(created)
+	sort.SliceStable(byUse, func(i, j int) bool { return byUse[i].SeenCount > byUse[j].SeenCount })
+	keep, capped := map[string]bool{}, map[string]int{}
+	for _, c := range byUse {
+		tool := toolOf(c)
+		switch {
+		case known[c.ID]:
+		case !toolAllowed(tool, only, exclude):
+			continue
+		case toolCap(tool) > 0 && have[tool] >= toolCap(tool):
+			capped[tool]++
+			continue
+		default:
+			have[tool]++
+		}
+		keep[c.ID] = true
+	}
+	return filterCards(created, func(c Card) bool { return keep[c.ID] }), capped
+}
+
+// generateAll runs every card generator over srcs. Cards in existing that
+// show up again are updated in place (seen counts, origins).
+func generateAll(srcs []ingest.Source, w ingest.Window, existing []Card, now time.Time) []Card {
+	events := ParseHistory(srcs, w)
+	out := GenerateCards(events, existing)
+	for _, gen := range []func() []Card{
+		func() []Card { return GenerateSequenceCards(ParseTimeline(srcs, w), existing, now) },
+		func() []Card { return GeneratePipelineCards(events, existing, now) },
+		func() []Card { return GenerateComboCards(events, existing, now) },
+		func() []Card { return GenerateDangerCards(events, existing, now) },
+	} {
+		out = append(out, gen()...)
+		ingestMeter.found(len(out))
+	}
+	return out
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"memento/pkg/storage"
+)
+
+func TestIngestTwoSources(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer func(s storage.Storage) { store = s }(store)
+	mem := storage.NewMemory(nil)
+	store = mem
+
+	dir := t.TempDir()
+	for _, name := range []string{"laptop_history", "server_history"} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(": 1700000000:0;tar -xzf archive.tgz -C /tmp/out\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := runIngest([]string{"--quiet", "--shell", "zsh", "--file", p}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deck, err := mem.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deck) != 1 {
+		t.Fatalf("%d cards, want 1: %+v", len(deck), deck)
+	}
+	c := deck[0]
+	if c.SeenCount != 2 {
+		t.Errorf("SeenCount = %d, want 2", c.SeenCount)
+	}
+	files := map[string]bool{}
+	for _, o := range c.Origins {
+		files[filepath.Base(o.File)] = true
+	}
+	if len(c.Origins) != 2 || !files["laptop_history"] || !files["server_history"] {
+		t.Errorf("origins %+v, want both history files", c.Origins)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"memento/pkg/storage"
+)
+
+// runRebuild restores cards.json from the journal.
+func runRebuild(args []string) error {
+	if err := jsonOnly("rebuild"); err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("rebuild", flag.ExitOnError)
+	dry := fs.Bool("dry-run", false, "only report what the journal holds")
+	_ = fs.Parse(args)
+	cards, n, err := storage.ReplayJournal()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Journal: %d entries → %d cards\n", n, len(cards))
+	if *dry {
+		return nil
+	}
+	p, err := storage.CardsPath()
+	if err != nil {
+		return err
+	}
+	if err := storage.Snapshot(p, time.Now()); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(cards, "", " ")
+	if err != nil {
+		return err
+	}
+	logger.Info("rebuild", "entries", n, "cards", len(cards))
+	if err := storage.WriteCards(p, b); err != nil {
+		return err
+	}
+	fmt.Println("Rebuilt", storage.TildePath(p), "(the previous version is in snapshots/)")
+	return nil
+}
+
+// runCompact squashes the journal to one base entry per live card.
+func runCompact(args []string) error {
+	if err := jsonOnly("compact"); err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("compact", flag.ExitOnError)
+	_ = fs.Parse(args)
+	cards, n, err := storage.ReplayJournal()
+	if err != nil {
+		return err
+	}
+	jp, err := storage.JournalPath()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	now := time.Now()
+	for i := range cards {
+		if err := enc.Encode(storage.JournalEntry{At: now, Op: "base", By: "compact", ID: cards[i].ID, Card: &cards[i]}); err != nil {
+			return err
+		}
+	}
+	old, _ := os.Stat(jp)
+	if err := storage.WriteFileAtomic(jp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	logger.Info("compact", "entries", n, "cards", len(cards))
+	fmt.Printf("Compacted %s: %d entries (%s) → %d (%s)\n", storage.TildePath(jp), n, humanBytes(old.Size()), len(cards), humanBytes(int64(buf.Len())))
+	return nil
+}
+
+func humanBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
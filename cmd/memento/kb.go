@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"memento/pkg/storage"
+)
+
+// The knowledge base (flag pairs and descriptions) ships with the binary;
+// `memento kb update` fetches a newer published copy into the data dir, which
+// then sits between the bundled tables and anything learned locally.
+
+const defaultKBURL = "https://github.com/kamaterasu/Memonto/releases/latest/download/kb.json"
+
+// kbURL is kb.url, set by configure.
+var kbURL = defaultKBURL
+
+// KB is the published artifact; its checksum is published next to it as
+// <url>.sha256 (sha256sum format).
+type KB struct {
+	Version      string    `json:"version"`
+	Flags        flagTable `json:"flags"`
+	Descriptions flagTable `json:"descriptions"`
+}
+
+func kbPath() (string, error) {
+	d, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "kb.json"), nil
+}
+
+// loadKB reads the downloaded knowledge base; a zero KB when there is none.
+func loadKB() (KB, error) {
+	var kb KB
+	p, err := kbPath()
+	if err != nil {
+		return kb, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return kb, nil
+	}
+	if err != nil {
+		return kb, err
+	}
+	return kb, json.Unmarshal(b, &kb)
+}
+
+// mergeTable copies src's entries into dst, src winning.
+func mergeTable(dst, src flagTable) {
+	for k, m := range src {
+		if dst[k] == nil {
+			dst[k] = map[string]string{}
+		}
+		for f, v := range m {
+			dst[k][f] = v
+		}
+	}
+}
+
+// parseSum takes the first field of a sha256sum line.
+func parseSum(b []byte) (string, error) {
+	f := strings.Fields(string(b))
+	if len(f) == 0 || len(f[0]) != 64 {
+		return "", fmt.Errorf("not a sha256 checksum: %q", firstLine(strings.TrimSpace(string(b))))
+	}
+	return strings.ToLower(f[0]), nil
+}
+
+// fetchKB downloads and verifies the artifact at url.
+func fetchKB(url string) (KB, []byte, error) {
+	var kb KB
+	b, err := fetchURL(url)
+	if err != nil {
+		return kb, nil, err
+	}
+	sb, err := fetchURL(url + ".sha256")
+	if err != nil {
+		return kb, nil, fmt.Errorf("checksum: %w", err)
+	}
+	want, err := parseSum(sb)
+	if err != nil {
+		return kb, nil, err
+	}
+	if got := storage.Checksum(b); got != want {
+		return kb, nil, fmt.Errorf("checksum mismatch: got %s, published %s", got[:12], want[:12])
+	}
+	if err := json.Unmarshal(b, &kb); err != nil {
+		return kb, nil, fmt.Errorf("%s: %w", url, err)
+	}
+	if len(kb.Flags) == 0 && len(kb.Descriptions) == 0 {
+		return kb, nil, fmt.Errorf("%s: empty knowledge base", url)
+	}
+	return kb, b, nil
+}
+
+func kbCounts(flags, descs flagTable) string {
+	n := 0
+	for _, m := range descs {
+		n += len(m)
+	}
+	return fmt.Sprintf("%d tools with flag pairs, %d flag descriptions", len(flags), n)
+}
+
+func runKB(args []string) error {
+	if len(args) == 0 || args[0] != "update" {
+		if len(args) > 0 && args[0] != "status" {
+			return fmt.Errorf("usage: memento kb [status] | update [--url u]")
+		}
+		kb, err := loadKB()
+		if err != nil {
+			return err
+		}
+		bf, bd := flagTable{}, flagTable{}
+		_ = json.Unmarshal(bundledFlags, &bf)
+		_ = json.Unmarshal(bundledDescriptions, &bd)
+		fmt.Println("bundled: ", kbCounts(bf, bd))
+		if kb.Version == "" {
+			fmt.Println("updated:  none (memento kb update)")
+			return nil
+		}
+		p, _ := kbPath()
+		fmt.Printf("updated:  %s, %s (%s)\n", kb.Version, kbCounts(kb.Flags, kb.Descriptions), storage.TildePath(p))
+		return nil
+	}
+	fs := flag.NewFlagSet("kb update", flag.ExitOnError)
+	url := fs.String("url", kbURL, "artifact to fetch; its checksum is read from <url>.sha256")
+	_ = fs.Parse(args[1:])
+
+	old, err := loadKB()
+	if err != nil {
+		logger.Warn("kb: replacing unreadable knowledge base", "err", err)
+	}
+	kb, b, err := fetchKB(*url)
+	if err != nil {
+		return err
+	}
+	p, err := kbPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	if err := storage.WriteFileAtomic(p, b, 0o644); err != nil {
+		return err
+	}
+	logger.Info("kb update", "version", kb.Version, "from", old.Version, "url", *url)
+	if old.Version == kb.Version {
+		fmt.Printf("Knowledge base %s is current (%s).\n", kb.Version, kbCounts(kb.Flags, kb.Descriptions))
+		return nil
+	}
+	fmt.Printf("Updated knowledge base to %s: %s.\n", kb.Version, kbCounts(kb.Flags, kb.Descriptions))
+	if longFlags && len(kb.Flags) > 0 {
+		fmt.Println("New flag pairs change normalization; run memento rehash to merge existing cards.")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds the review TUI bindings; any of them can be rebound through
+// the "keys" section of config.json, e.g. {"keys": {"next": ["n", "space"]}}.
+type keyMap struct {
+	Check       key.Binding
+	Complete    key.Binding
+	Next        key.Binding
+	Quit        key.Binding
+	ForceQuit   key.Binding
+	Help        key.Binding
+	Snooze      key.Binding
+	Star        key.Binding
+	Copy        key.Binding
+	Hint        key.Binding
+	Skip        key.Binding
+	AutoAdvance key.Binding
+	AltAnswers  key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Check:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "check answer")),
+		Complete:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "complete flag")),
+		Next:        key.NewBinding(key.WithKeys("n", "right", "tab"), key.WithHelp("n/→", "next card")),
+		Quit:        key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit (after answering)")),
+		ForceQuit:   key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit now")),
+		Help:        key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "toggle help")),
+		Snooze:      key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "snooze card")),
+		Star:        key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "star/unstar")),
+		Copy:        key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy command")),
+		Hint:        key.NewBinding(key.WithKeys("alt+h"), key.WithHelp("alt+h", "hint (counts as hard)")),
+		Skip:        key.NewBinding(key.WithKeys("alt+k"), key.WithHelp("alt+k", "skip to end")),
+		AutoAdvance: key.NewBinding(key.WithKeys("alt+a"), key.WithHelp("alt+a", "toggle auto-advance")),
+		AltAnswers:  key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "edit accepted answers")),
+	}
+}
+
+// bindings exposes the keymap by config name.
+func (k *keyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"check": &k.Check, "complete": &k.Complete, "next": &k.Next,
+		"quit": &k.Quit, "force_quit": &k.ForceQuit, "help": &k.Help,
+		"snooze": &k.Snooze, "star": &k.Star, "copy": &k.Copy, "hint": &k.Hint, "skip": &k.Skip,
+		"auto_advance": &k.AutoAdvance, "alt_answers": &k.AltAnswers,
+	}
+}
+
+func keyMapFromConfig(overrides map[string][]string) (keyMap, error) {
+	km := defaultKeyMap()
+	b := km.bindings()
+	for name, keys := range overrides {
+		kb, ok := b[name]
+		if !ok {
+			return km, fmt.Errorf("keys: unknown action %q", name)
+		}
+		if len(keys) == 0 {
+			return km, fmt.Errorf("keys: %s needs at least one key", name)
+		}
+		kb.SetKeys(keys...)
+		kb.SetHelp(keys[0], kb.Help().Desc)
+	}
+	return km, nil
+}
+
+// answeringHelp / checkingHelp are the one-line hints for each review state.
+func (k keyMap) answeringHelp() []key.Binding {
+	return []key.Binding{k.Check, k.Complete, k.Hint, k.Skip, k.Snooze, k.Help}
+}
+
+func (k keyMap) checkingHelp() []key.Binding {
+	return []key.Binding{k.Next, k.Copy, k.AltAnswers, k.Star, k.Quit, k.Help}
+}
+
+// ShortHelp and FullHelp implement help.KeyMap for the overlay.
+func (k keyMap) ShortHelp() []key.Binding { return k.answeringHelp() }
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Check, k.Complete, k.Hint, k.Skip, k.Snooze},
+		{k.Next, k.Copy, k.AltAnswers, k.Quit, k.ForceQuit},
+		{k.Star, k.AutoAdvance, k.Help},
+	}
+}
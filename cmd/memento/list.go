@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// selectCards loads the deck and returns it with the indexes matching q.
+func selectCards(q Query) ([]Card, []int, error) {
+	cards, err := LoadCards()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	idx := []int{}
+	for i, c := range cards {
+		if q.Match(c, now) {
+			idx = append(idx, i)
+		}
+	}
+	return cards, idx, nil
+}
+
+func cardRow(c Card, now time.Time) string {
+	prompt := firstLine(c.Prompt)
+	if c.Pinned {
+		prompt = "[pinned] " + prompt
+	}
+	if c.Starred {
+		prompt = "★ " + prompt
+	}
+	return fmt.Sprintf("%s  box %d  %-14s  %-13s %s", shortID(c.ID), c.Box, dueLabel(c, now), c.Kind(), prompt)
+}
+
+func printCardRow(c Card) { fmt.Println(cardRow(c, time.Now())) }
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	by := fs.String("sort", "", "order by "+strings.Join(cardSorts, "|")+" (default: stored order)")
+	reverse := fs.Bool("reverse", false, "reverse the sort order")
+	_ = fs.Parse(args)
+	q, err := queryArg(fs.Args())
+	if err != nil {
+		return err
+	}
+	cards, idx, err := selectCards(q)
+	if err != nil {
+		return err
+	}
+	sel := []Card{}
+	for _, i := range idx {
+		sel = append(sel, cards[i])
+	}
+	if *by != "" {
+		if err := sortCards(sel, *by, *reverse); err != nil {
+			return err
+		}
+	} else if *reverse {
+		slices.Reverse(sel)
+	}
+	for _, c := range sel {
+		printCardRow(c)
+	}
+	fmt.Printf("%d of %d cards\n", len(idx), len(cards))
+	return nil
+}
+
+func runDelete(args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	dry := fs.Bool("dry-run", false, "only list what would be deleted")
+	_ = fs.Parse(args)
+	q, err := queryArg(fs.Args())
+	if err != nil {
+		return err
+	}
+	if q.Empty() {
+		return fmt.Errorf("delete needs a query (refusing to delete every card)")
+	}
+	cards, idx, err := selectCards(q)
+	if err != nil {
+		return err
+	}
+	for _, i := range idx {
+		printCardRow(cards[i])
+	}
+	if len(idx) == 0 || *dry {
+		fmt.Printf("%d cards match\n", len(idx))
+		return nil
+	}
+	if !*yes && !confirm(fmt.Sprintf("Delete %d cards?", len(idx))) {
+		return nil
+	}
+	drop := map[string]bool{}
+	for _, i := range idx {
+		drop[cards[i].ID] = true
+	}
+	n := 0
+	err = saveUndoable(undoEntry{What: "delete " + q.Src}, func(cards []Card) []Card {
+		kept := slices.DeleteFunc(cards, func(c Card) bool { return drop[c.ID] })
+		n = len(cards) - len(kept)
+		return kept
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d cards\n", n)
+	return nil
+}
+
+func runTag(args []string) error {
+	fs := flag.NewFlagSet("tag", flag.ExitOnError)
+	add := fs.String("add", "", "comma-separated tags to add")
+	remove := fs.String("remove", "", "comma-separated tags to remove")
+	_ = fs.Parse(args)
+	if *add == "" && *remove == "" {
+		return fmt.Errorf("tag needs --add and/or --remove")
+	}
+	q, err := queryArg(fs.Args())
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	changed, matched := 0, 0
+	err = saveUndoable(undoEntry{What: "tag " + q.Src}, func(cards []Card) []Card {
+		for i := range cards {
+			if !q.Match(cards[i], now) {
+				continue
+			}
+			matched++
+			if retag(&cards[i], splitList(*add), splitList(*remove)) {
+				changed++
+			}
+		}
+		if changed == 0 {
+			return nil
+		}
+		return cards
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Retagged %d of %d matching cards\n", changed, matched)
+	return nil
+}
+
+// retag adds and removes tags, reporting whether anything changed.
+func retag(c *Card, add, remove []string) bool {
+	before := strings.Join(c.Tags, ",")
+	tags := []string{}
+	for _, t := range c.Tags {
+		if !contains(remove, t) {
+			tags = append(tags, t)
+		}
+	}
+	c.Tags = unique(append(tags, add...))
+	return strings.Join(c.Tags, ",") != before
+}
+
+func splitList(s string) []string {
+	out := []string{}
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func contains(xs []string, s string) bool {
+	for _, x := range xs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func confirm(prompt string) bool {
+	fmt.Print(prompt + " [y/N] ")
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(line), "y")
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"memento/pkg/ingest"
+	"memento/pkg/storage"
+)
+
+// LogConfig: operations (ingest decisions, scrubbing, writes, scheduling) are
+// logged as JSON lines to memento.log in the state dir, rotated at max_size_mb.
+type LogConfig struct {
+	Level     string `json:"level"`       // debug|info|warn|error
+	MaxSizeMB int    `json:"max_size_mb"` // rotate past this size
+	Keep      int    `json:"keep"`        // rotated files to keep (memento.log.1, .2, …)
+}
+
+// logger discards until openLog runs; --verbose mirrors it to stderr.
+var logger = slog.New(slog.DiscardHandler)
+
+// quietStderr silences the --verbose mirror while the TUI owns the terminal.
+var quietStderr atomic.Bool
+
+func stateDir() (string, error) {
+	if d := os.Getenv("XDG_STATE_HOME"); d != "" {
+		return filepath.Join(d, "memento"), nil
+	}
+	h, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(h, ".local", "state", "memento"), nil
+}
+
+func logPath() (string, error) {
+	d, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "memento.log"), nil
+}
+
+func parseLogLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown level %q (want debug, info, warn or error)", s)
+	}
+	return l, nil
+}
+
+// openLog points logger at the rotating log file, and at stderr too when verbose.
+func openLog(cfg LogConfig, verbose bool) error {
+	level, err := parseLogLevel(cfg.Level)
+	if err != nil {
+		return fmt.Errorf("log.level: %w", err)
+	}
+	p, err := logPath()
+	if err != nil {
+		return err
+	}
+	f := &rotatingFile{path: p, max: int64(cfg.MaxSizeMB) << 20, keep: cfg.Keep}
+	if err := f.open(); err != nil {
+		return err
+	}
+	var h slog.Handler = slog.NewJSONHandler(f, &slog.HandlerOptions{Level: level})
+	if verbose {
+		h = teeHandler{h, stderrHandler{slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})}}
+	}
+	logger = slog.New(h)
+	storage.Logger = logger
+	ingest.Logger = logger
+	return nil
+}
+
+// verboseFlag removes --verbose from args wherever it appears.
+func verboseFlag(args []string) ([]string, bool) {
+	out, v := []string{}, false
+	for _, a := range args {
+		if a == "--verbose" {
+			v = true
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, v
+}
+
+// rotatingFile appends to path and shifts it to path.1 (… path.keep) once
+// it would grow past max bytes.
+type rotatingFile struct {
+	mu   sync.Mutex
+	path string
+	max  int64
+	keep int
+	f    *os.File
+	size int64
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, st.Size()
+	return nil
+}
+
+func (r *rotatingFile) rotate() error {
+	r.f.Close()
+	for i := r.keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.keep > 0 {
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.max > 0 && r.size > 0 && r.size+int64(len(p)) > r.max {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// teeHandler sends each record to both handlers.
+type teeHandler struct{ a, b slog.Handler }
+
+func (t teeHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return t.a.Enabled(ctx, l) || t.b.Enabled(ctx, l)
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	for _, h := range []slog.Handler{t.a, t.b} {
+		if h.Enabled(ctx, r.Level) {
+			if e := h.Handle(ctx, r.Clone()); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+func (t teeHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	return teeHandler{t.a.WithAttrs(as), t.b.WithAttrs(as)}
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	return teeHandler{t.a.WithGroup(name), t.b.WithGroup(name)}
+}
+
+// stderrHandler drops records while quietStderr is set.
+type stderrHandler struct{ slog.Handler }
+
+func (s stderrHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return !quietStderr.Load() && s.Handler.Enabled(ctx, l)
+}
+
+func (s stderrHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	return stderrHandler{s.Handler.WithAttrs(as)}
+}
+
+func (s stderrHandler) WithGroup(name string) slog.Handler {
+	return stderrHandler{s.Handler.WithGroup(name)}
+}
+
+// logCmd shortens a command for log lines.
+func logCmd(s string) string {
+	if r := []rune(firstLine(s)); len(r) > 120 {
+		return string(r[:119]) + "…"
+	}
+	return firstLine(s)
+}
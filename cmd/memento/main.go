@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"memento/pkg/storage"
+)
+
+const usageText = `Memento — Shell History for Your Brain
+Usage (add --verbose anywhere to mirror the operation log in $XDG_STATE_HOME/memento/memento.log to stderr):
+memento setup # guided setup: history files, masking, secrets, shell hooks, first ingest (runs on first launch)
+memento ingest [--file f --shell zsh|bash|fish] [--since 30d | --between A..B] [--only git,kubectl] [--exclude docker] [--audit-scrub] [--quiet] # parse bash/zsh history → generate/update cards (progress bar on a terminal; --quiet for cron)
+memento review [--id prefix] [--query q] [--resume|--fresh] [--host h] [--skip-missing-tools] [--mode all|sequence|pipeline|comprehension|danger|combo] # TUI daily review (Leitner boxes)
+memento practice [--query q] [--count 10] [--concrete] # blind typing arena: goal + tool only, whole command, no scheduling; --concrete fills placeholders with real values
+memento catchup [--days 7] [--dry-run] # spread a big backlog over several days instead of one session
+memento stats [--days 30] [--export stats.csv|stats.json|-] [--format csv|json] # review totals and per-tag retention; --export writes daily counts, accuracy and retention in long format
+memento simulate [--days 90] [--add 200] [--new 3] [--runs 50] # forecast daily review load (Monte Carlo on your accuracy)
+memento pause [--until 2025-01-05 | --for 2w] # vacation: freeze scheduling, shift everything on resume
+memento resume # end a pause early
+memento status [--format plain|waybar|polybar|i3blocks] # due count for status bars
+memento daemon [--poll 1m] [--once] [--metrics :9310] # background notifier (webhook from config); Prometheus /metrics
+memento mcp # MCP server on stdio (browse/search/quiz for LLM assistants)
+memento import --format anki <deck.apkg> # import cards from another deck
+memento export [--format markdown] [--split-by none|tag] [--out dir] [--tag t] [--query q] # export cards
+memento cheatsheet [--tag t] [--min-box 4] [--out file.html] # printable sheet of mastered cards
+memento discover <tool> # learn a new tool: cards from tldr-pages examples
+memento deck list | install <name|file|url> | export --tag t [--out f] # shareable decks
+memento triage [--list] [--accept-all|--reject-all] # accept or reject borderline cards ingest staged (ingest.accept_score)
+memento remember [--last | --clipboard | -- "<command>"] # card a command, skipping the heuristic
+memento init zsh|bash|fish # print shell integration (eval "$(memento init zsh)")
+memento enrich [--tag t] [--limit n] # add "what does this do?" cards (tldr / enrich.command / whatis)
+memento audit # private report of redacted history lines and secrets left in cards.json
+memento prune --missing-tools [--dry-run] # drop cards for tools not installed here
+memento suggest # tools on PATH with no cards, and how to get some
+memento list [--sort due|box|seen|tool|created] [--reverse] [query] # list cards, e.g. memento list 'tag:git box:<3 due:today seen:>5 "rebase"' (is:starred for favourites)
+memento browse [--sort due] [--reverse] [query] # scrollable card browser (s: cycle sort, r: reverse, m: mark/merge)
+memento delete [--dry-run] [--yes] <query> # delete matching cards
+memento tag [--add a,b] [--remove c] <query> # retag matching cards
+memento bulk --query q [--set-tag a,b] [--unset-tag c] [--suspend|--unsuspend] [--box n] [--due-now] [--answer-pattern '-j*'|/re/|off] [--strictness exact|case-insensitive|fuzzy|substring|default] [--dry-run] # batch-edit matching cards
+memento validate [--fix] # check cards.json: schema, duplicate IDs, empty answers, bad dates
+memento dupes [--similarity 0.85] [--merge [--yes]] # cards with the same answer and near-identical prompts; merge keeps progress, answers and origins
+memento merge-cards <keep-id> <merge-id> # fold one card into another (m twice in memento browse); the merged ID stays gone on ingest
+memento weak [--min 3] [--limit 10] # tools and flags you keep failing, with suggestions
+memento aging [--backlog-days 14] [--stale 6mo] # long-overdue cards and commands you stopped running
+memento pin [--off] [id] # keep a card at the front of every session until unpinned (no id: list pinned)
+memento rehash [--dry-run] # move cards to new IDs after normalizer changes, keeping progress and review history
+memento undo [--list] [--dry-run] [--force] # revert the last delete, prune, bulk, tag or merge (the last 20 are kept)
+memento redo [--dry-run] [--force] # reapply the last undone command
+memento rebuild [--dry-run] # restore cards.json from the change journal (journal.jsonl)
+memento compact # squash the change journal to one entry per card
+memento flags [tool [sub]] | learn <tool> [sub] # short/long flag table (used for answers and normalization); learn scrapes --help
+memento kb [status] | update [--url u] # flag knowledge base: fetch a newer published copy (checksum-verified)
+memento show <id> # card details: JSON, variants, review history
+memento self-update [--check] [--force] [--insecure] # replace this binary with the latest GitHub release (signature-verified; --insecure: checksum only, for builds without a release key)
+memento bench ingest --file f [--shell zsh|bash|fish] [--runs 5] # time reading, scrubbing, normalizing and card generation over a history file
+memento version [--json] # version, commit, build date and data schema
+memento help # show this help`
+
+func usage() { fmt.Println(usageText) }
+
+// subcommands lists command names from usageText (used for shell completions).
+func subcommands() []string {
+	out := []string{}
+	for _, line := range strings.Split(usageText, "\n") {
+		if f := strings.Fields(line); len(f) > 1 && f[0] == "memento" {
+			out = append(out, f[1])
+		}
+	}
+	return out
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		return
+	}
+	args, verbose := verboseFlag(os.Args[1:])
+	args, cpuProfile, memProfile := profileFlags(args)
+	os.Args = append(os.Args[:1], args...)
+	if len(os.Args) < 2 {
+		usage()
+		return
+	}
+	sub := os.Args[1]
+	if err := startProfiling(cpuProfile, memProfile); err != nil {
+		fatal(fmt.Errorf("profile: %w", err))
+	}
+	defer stopProfiling()
+	cfg, err := LoadConfig()
+	if err != nil {
+		fatal(fmt.Errorf("config: %w", err))
+	}
+	if sub == "capture" { // runs after every prompt: no storage, log or pause handling
+		if err := configureScrub(cfg); err != nil {
+			fatal(fmt.Errorf("config: %w", err))
+		}
+		if err := runCapture(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	if err := configure(cfg); err != nil {
+		fatal(fmt.Errorf("config: %w", err))
+	}
+	if err := openLog(cfg.Log, verbose); err != nil {
+		fatal(fmt.Errorf("log: %w", err))
+	}
+	logger.Debug("run", "command", sub, "args", os.Args[2:], "version", buildInfo().Version)
+	storage.JournalBy = sub
+	if err := applyPause(time.Now()); err != nil {
+		fatal(fmt.Errorf("pause: %w", err))
+	}
+	if (sub == "ingest" || sub == "review") && len(os.Args) == 2 && firstRun() && interactive() {
+		if err := runSetup(nil); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	switch sub {
+	case "setup":
+		if err := runSetup(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "ingest":
+		if err := runIngest(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "review":
+		if err := runReview(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "practice":
+		if err := runPractice(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "catchup":
+		if err := runCatchUp(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "stats":
+		if err := runStats(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "simulate":
+		if err := runSimulate(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "pause":
+		if err := runPause(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "resume":
+		if err := runResume(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "status":
+		if err := runStatus(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "daemon":
+		if err := runDaemon(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "mcp":
+		if err := runMCPServer(); err != nil {
+			fatal(err)
+		}
+	case "import":
+		if err := runImport(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "export":
+		if err := runExport(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "cheatsheet":
+		if err := runCheatsheet(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "discover":
+		if err := runDiscover(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "deck":
+		if err := runDeck(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "triage":
+		if err := runTriage(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "remember":
+		if err := runRemember(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "init":
+		if err := runInit(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "enrich":
+		if err := runEnrich(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "audit":
+		if err := runAudit(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "prune":
+		if err := runPrune(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "suggest":
+		if err := runSuggest(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "list":
+		if err := runList(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "browse":
+		if err := runBrowse(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "delete":
+		if err := runDelete(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "tag":
+		if err := runTag(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "bulk":
+		if err := runBulk(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "validate":
+		if err := runValidate(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "dupes":
+		if err := runDupes(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "merge-cards":
+		if err := runMergeCards(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "weak":
+		if err := runWeak(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "aging":
+		if err := runAging(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "pin":
+		if err := runPin(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "rehash":
+		if err := runRehash(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "undo":
+		if err := runUndo(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "redo":
+		if err := runRedo(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "rebuild":
+		if err := runRebuild(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "compact":
+		if err := runCompact(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "flags":
+		if err := runFlags(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "kb":
+		if err := runKB(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "show":
+		if err := runShow(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "self-update":
+		if err := runSelfUpdate(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "bench":
+		if err := runBench(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "version", "--version":
+		if err := runVersion(os.Args[2:]); err != nil {
+			fatal(err)
+		}
+	case "help", "-h", "--help":
+		usage()
+	default:
+		fmt.Println("Unknown command:", sub)
+		usage()
+	}
+}
+
+func fatal(err error) { stopProfiling(); fmt.Fprintln(os.Stderr, "error:", err); os.Exit(1) }
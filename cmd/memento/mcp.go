@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Minimal MCP (Model Context Protocol) server over stdio: newline-delimited JSON-RPC 2.0.
+
+const mcpProtocolVersion = "2024-11-05"
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+func schema(props map[string]any, required ...string) map[string]any {
+	s := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+var mcpTools = []mcpTool{
+	{"list_cards", "List flashcards, optionally filtered by tag or due status.", schema(map[string]any{
+		"tag":      map[string]any{"type": "string"},
+		"due_only": map[string]any{"type": "boolean"},
+	})},
+	{"search_cards", "Full-text search over card commands, prompts, answers and tags.", schema(map[string]any{
+		"query": map[string]any{"type": "string"},
+	}, "query")},
+	{"get_card", "Show one card by ID (or unique ID prefix).", schema(map[string]any{
+		"id": map[string]any{"type": "string"},
+	}, "id")},
+	{"quiz_me", "Return the next due card's prompt (answer hidden). Optional tag filter.", schema(map[string]any{
+		"tag": map[string]any{"type": "string"},
+	})},
+	{"answer_card", "Grade an answer for a card and update its schedule.", schema(map[string]any{
+		"id":     map[string]any{"type": "string"},
+		"answer": map[string]any{"type": "string"},
+	}, "id", "answer")},
+}
+
+func runMCP(in io.Reader, out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	s := bufio.NewScanner(in)
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		var req rpcRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			_ = enc.Encode(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{-32700, "parse error"}})
+			continue
+		}
+		if len(req.ID) == 0 {
+			continue // notification
+		}
+		result, rerr := mcpDispatch(req)
+		resp := rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rerr}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+func mcpDispatch(req rpcRequest) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "memento", "version": buildInfo().Version},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": mcpTools}, nil
+	case "tools/call":
+		var p struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{-32602, "invalid params"}
+		}
+		text, err := mcpCall(p.Name, p.Arguments)
+		if err != nil {
+			return mcpText(err.Error(), true), nil
+		}
+		return mcpText(text, false), nil
+	default:
+		return nil, &rpcError{-32601, "method not found: " + req.Method}
+	}
+}
+
+func mcpText(s string, isErr bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]any{{"type": "text", "text": s}},
+		"isError": isErr,
+	}
+}
+
+func argString(args map[string]any, k string) string {
+	s, _ := args[k].(string)
+	return s
+}
+
+func mcpCall(name string, args map[string]any) (string, error) {
+	cards, err := LoadCards()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	switch name {
+	case "list_cards":
+		tag := argString(args, "tag")
+		dueOnly, _ := args["due_only"].(bool)
+		var lines []string
+		for _, c := range cards {
+			if tag != "" && !hasTag(c, tag) {
+				continue
+			}
+			if dueOnly && !isDue(c, now) {
+				continue
+			}
+			lines = append(lines, cardLine(c))
+		}
+		if len(lines) == 0 {
+			return "No matching cards.", nil
+		}
+		return strings.Join(lines, "\n"), nil
+	case "search_cards":
+		q := strings.ToLower(argString(args, "query"))
+		var lines []string
+		for _, c := range cards {
+			hay := strings.ToLower(strings.Join(append([]string{c.Command, c.Prompt, c.Answer}, c.Tags...), " "))
+			if strings.Contains(hay, q) {
+				lines = append(lines, cardLine(c))
+			}
+		}
+		if len(lines) == 0 {
+			return "No matching cards.", nil
+		}
+		return strings.Join(lines, "\n"), nil
+	case "get_card":
+		i, err := findCard(cards, argString(args, "id"))
+		if err != nil {
+			return "", err
+		}
+		b, err := json.MarshalIndent(cards[i], "", "  ")
+		return string(b), err
+	case "quiz_me":
+		tag := argString(args, "tag")
+		for _, c := range DueCards(cards, now) {
+			if tag != "" && !hasTag(c, tag) {
+				continue
+			}
+			return fmt.Sprintf("Card %s (tags: %s)\n%s\nHint: %s", shortID(c.ID), strings.Join(c.Tags, ", "), c.Prompt, c.Hint), nil
+		}
+		return "Nothing due. You're done for today.", nil
+	case "answer_card":
+		var line string
+		var ferr error
+		err := updateCards(func(cards []Card) []Card {
+			i, err := findCard(cards, argString(args, "id"))
+			if err != nil {
+				ferr = err
+				return nil
+			}
+			ans := argString(args, "answer")
+			correct := checkAnswer(cards[i], ans)
+			if _, ferr = gradeAndLog(&cards[i], ans, correct, false, 1, 0, now); ferr != nil {
+				return nil
+			}
+			line = feedbackLine(correct, cards[i])
+			return cards
+		})
+		if err == nil {
+			err = ferr
+		}
+		return line, err
+	default:
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+}
+
+func cardLine(c Card) string {
+	return fmt.Sprintf("%s %s  →  %s", shortID(c.ID), c.String(), c.Answer)
+}
+
+func runMCPServer() error { return runMCP(os.Stdin, os.Stdout) }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// mergePair folds drop into keep: keep's question stays, the stronger of the
+// two schedules wins, and the rest combines as in mergeDupes.
+func mergePair(keep, drop Card) Card {
+	if keeperLess(drop, keep) {
+		keep.Box, keep.NextDue, keep.Streak = drop.Box, drop.NextDue, drop.Streak
+		keep.Stability, keep.Difficulty = drop.Stability, drop.Difficulty
+	}
+	kind := drop.Kind() // keep's kind stands
+	drop.Tags = slices.DeleteFunc(slices.Clone(drop.Tags), func(t string) bool { return t == kind })
+	return mergeDupes(keep, []Card{drop})
+}
+
+// saveMerges replaces merged cards, drops the ones in moved (old ID → the ID
+// it went into), points their logged reviews at the survivors and tombstones
+// the old IDs in staging so ingest doesn't bring them back. The other cards
+// are taken from the deck as it is when saving.
+func saveMerges(what string, merged map[string]Card, moved map[string]string) (int, error) {
+	reviews, err := LoadReviews()
+	if err != nil {
+		return 0, err
+	}
+	e := undoEntry{What: what, Moved: moved, Reviews: map[string][]time.Time{}}
+	for _, r := range reviews {
+		if _, ok := moved[r.CardID]; ok {
+			e.Reviews[r.CardID] = append(e.Reviews[r.CardID], r.At)
+		}
+	}
+	err = saveUndoable(e, func(cards []Card) []Card {
+		out := make([]Card, 0, len(cards))
+		for _, c := range cards {
+			if _, gone := moved[c.ID]; gone {
+				continue
+			}
+			if m, ok := merged[c.ID]; ok {
+				c = m
+			}
+			out = append(out, c)
+		}
+		return out
+	})
+	if err != nil {
+		return 0, err
+	}
+	st, err := loadStaging()
+	if err != nil {
+		return 0, err
+	}
+	for old, id := range moved {
+		st.Rejected[old] = "merged into " + shortID(id)
+	}
+	if err := saveStaging(st); err != nil {
+		return 0, fmt.Errorf("cards saved, but merged IDs were not tombstoned: %w", err)
+	}
+	n, err := renameReviews(moved)
+	if err != nil {
+		return 0, fmt.Errorf("cards saved, but the review log was not updated: %w", err)
+	}
+	logger.Info("merge", "cards", len(moved), "into", len(merged), "reviews", n)
+	return n, nil
+}
+
+// mergeCards merges the card dropID into keepID on disk.
+func mergeCards(keepID, dropID string) (Card, error) {
+	cards, err := LoadCards()
+	if err != nil {
+		return Card{}, err
+	}
+	i, err := findCard(cards, keepID)
+	if err != nil {
+		return Card{}, err
+	}
+	j, err := findCard(cards, dropID)
+	if err != nil {
+		return Card{}, err
+	}
+	if i == j {
+		return Card{}, fmt.Errorf("can't merge card %s into itself", shortID(cards[i].ID))
+	}
+	k := mergePair(cards[i], cards[j])
+	_, err = saveMerges("merge "+shortID(cards[j].ID)+" into "+shortID(k.ID), map[string]Card{k.ID: k}, map[string]string{cards[j].ID: k.ID})
+	return k, err
+}
+
+func runMergeCards(args []string) error {
+	fs := flag.NewFlagSet("merge-cards", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: memento merge-cards <keep-id> <merge-id>")
+	}
+	k, err := mergeCards(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	printCardRow(k)
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"memento/pkg/srs"
+)
+
+// writeMetrics renders the Prometheus text format. Everything is derived from
+// cards.json and reviews.jsonl on each scrape, so counters survive restarts.
+func writeMetrics(w io.Writer, cards []Card, reviews []ReviewEntry, now time.Time) {
+	metric := func(name, typ, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	s := summarize(cards, now)
+	metric("memento_cards", "gauge", "Cards in the deck.")
+	fmt.Fprintf(w, "memento_cards %d\n", s.Total)
+	metric("memento_cards_due", "gauge", "Cards due for review now.")
+	fmt.Fprintf(w, "memento_cards_due %d\n", s.Due)
+
+	boxes := make([]int, srs.MaxBox()+1)
+	suspended, seen := 0, 0
+	for _, c := range cards {
+		boxes[min(max(c.Box, 1), srs.MaxBox())]++
+		if c.Suspended {
+			suspended++
+		}
+		seen += c.SeenCount
+	}
+	metric("memento_cards_box", "gauge", "Cards per Leitner box.")
+	for b := 1; b <= srs.MaxBox(); b++ {
+		fmt.Fprintf(w, "memento_cards_box{box=\"%d\"} %d\n", b, boxes[b])
+	}
+	metric("memento_cards_suspended", "gauge", "Suspended cards.")
+	fmt.Fprintf(w, "memento_cards_suspended %d\n", suspended)
+
+	correct := 0
+	for _, r := range reviews {
+		if r.Correct {
+			correct++
+		}
+	}
+	metric("memento_reviews_total", "counter", "Graded reviews by result.")
+	fmt.Fprintf(w, "memento_reviews_total{result=\"correct\"} %d\n", correct)
+	fmt.Fprintf(w, "memento_reviews_total{result=\"wrong\"} %d\n", len(reviews)-correct)
+	metric("memento_ingested_commands_total", "counter", "History commands ingest matched to a card (sum of seen counts).")
+	fmt.Fprintf(w, "memento_ingested_commands_total %d\n", seen)
+}
+
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	cards, err := LoadCards()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	reviews, err := LoadReviews()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	writeMetrics(w, cards, reviews, time.Now())
+}
+
+// serveMetrics exposes /metrics on addr until the listener fails.
+func serveMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", metricsHandler)
+	logger.Info("metrics", "addr", addr)
+	return http.ListenAndServe(addr, mux)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// The card model lives in pkg/cards; the command keeps its short names for it.
+type (
+	Card     = cards.Card
+	CardType = cards.CardType
+	Origin   = cards.Origin
+)
+
+const (
+	sequenceTag      = cards.SequenceTag
+	pipelineTag      = cards.PipelineTag
+	comprehensionTag = cards.ComprehensionTag
+	dangerTag        = cards.DangerTag
+	comboTag         = cards.ComboTag
+)
+
+func hasTag(c Card, tag string) bool                      { return cards.HasTag(c, tag) }
+func shortID(id string) string                            { return cards.ShortID(id) }
+func findCard(deck []Card, prefix string) (int, error)    { return cards.Find(deck, prefix) }
+func union(a, b []string) []string                        { return cards.Union(a, b) }
+func mergeOrigin(list []Origin, o Origin) []Origin        { return cards.MergeOrigin(list, o) }
+func UpsertCards(existing []Card, incoming []Card) []Card { return cards.Upsert(existing, incoming) }
+
+// isDue is Card.Due, and false for everything while scheduling is paused.
+func isDue(c Card, now time.Time) bool { return paused == nil && c.Due(now) }
+
+func originLabel(o Origin) string {
+	s := fmt.Sprintf("%s on %s (%s)", o.Shell, o.Host, o.File)
+	if !o.LastSeen.IsZero() {
+		now := time.Now()
+		s += fmt.Sprintf(", first seen %s, last %s", humanTime(o.FirstSeen, now), humanTime(o.LastSeen, now))
+	}
+	return s
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Summary is the snapshot posted to webhooks.
+type Summary struct {
+	Due           int       `json:"due"`
+	Total         int       `json:"total"`
+	ReviewedToday bool      `json:"reviewed_today"`
+	StreakAtRisk  bool      `json:"streak_at_risk"`
+	At            time.Time `json:"at"`
+}
+
+func summarize(cards []Card, now time.Time) Summary {
+	s := Summary{Due: len(DueCards(cards, now)), Total: len(cards), At: now}
+	today := dayStart(now)
+	for _, c := range cards {
+		if !c.LastReviewed.IsZero() && dayStart(c.LastReviewed).Equal(today) {
+			s.ReviewedToday = true
+			break
+		}
+	}
+	s.StreakAtRisk = s.Due > 0 && !s.ReviewedToday
+	return s
+}
+
+func (s Summary) text() string {
+	t := fmt.Sprintf("Memento: %d of %d cards due", s.Due, s.Total)
+	if s.StreakAtRisk {
+		t += " — no review yet today, streak at risk"
+	} else if s.ReviewedToday {
+		t += " — reviewed today ✔"
+	}
+	return t
+}
+
+func webhookPayload(format string, s Summary) ([]byte, error) {
+	switch format {
+	case "", "json":
+		return json.Marshal(s)
+	case "slack":
+		return json.Marshal(map[string]string{"text": s.text()})
+	case "discord":
+		return json.Marshal(map[string]string{"content": s.text()})
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q", format)
+	}
+}
+
+func PostWebhook(wh WebhookConfig, s Summary) error {
+	if wh.URL == "" {
+		return fmt.Errorf("no webhook url configured")
+	}
+	body, err := webhookPayload(wh.Format, s)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(wh.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"memento/pkg/srs"
+)
+
+func TestSummarizeDayStart(t *testing.T) {
+	defer func(h int) { srs.DayStartHour = h }(srs.DayStartHour)
+	srs.DayStartHour = 4
+	due := Card{ID: "due", Box: 1, NextDue: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)}
+	tests := []struct {
+		name          string
+		reviewed, now time.Time
+		today         bool
+	}{
+		{"1 AM counts as the evening before", time.Date(2024, 1, 30, 22, 0, 0, 0, time.Local), time.Date(2024, 1, 31, 1, 0, 0, 0, time.Local), true},
+		{"new day starts at 4 AM", time.Date(2024, 1, 31, 3, 0, 0, 0, time.Local), time.Date(2024, 1, 31, 9, 0, 0, 0, time.Local), false},
+		{"same calendar day after 4 AM", time.Date(2024, 1, 31, 5, 0, 0, 0, time.Local), time.Date(2024, 1, 31, 23, 0, 0, 0, time.Local), true},
+		{"never reviewed", time.Time{}, time.Date(2024, 1, 31, 9, 0, 0, 0, time.Local), false},
+	}
+	for _, tt := range tests {
+		s := summarize([]Card{due, {ID: "r", Box: 2, LastReviewed: tt.reviewed, NextDue: tt.now.Add(48 * time.Hour)}}, tt.now)
+		if s.ReviewedToday != tt.today || s.StreakAtRisk == tt.today {
+			t.Errorf("%s: reviewed today %v, streak at risk %v; want %v, %v", tt.name, s.ReviewedToday, s.StreakAtRisk, tt.today, !tt.today)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// partialPass is review.partial_pass, set by configure: the share of parts a
+// multi-part answer needs to count as right (graded hard when below 100%).
+var partialPass = 0.75
+
+// partResult is one independently scored blank or command token; Want is
+// empty for a token that was typed but not expected.
+type partResult struct {
+	Want, Got string
+	OK        bool
+}
+
+// scoreParts splits a card into independently scored parts: one per blank
+// when the prompt has several (answer words in order), one per token for
+// whole-command answers. ok is false for single-part cards.
+func scoreParts(c Card, ans string) (parts []partResult, ok bool) {
+	want := strings.Fields(c.Answer)
+	if specFor(c).command {
+		if len(want) < 2 {
+			return nil, false
+		}
+		got := map[string]int{}
+		typed := strings.Fields(normalizeCommand(ans))
+		for _, t := range typed {
+			got[t]++
+		}
+		for _, w := range want {
+			p := partResult{Want: w}
+			if got[w] > 0 {
+				got[w]--
+				p.Got, p.OK = w, true
+			}
+			parts = append(parts, p)
+		}
+		for _, t := range typed {
+			if got[t] > 0 {
+				got[t]--
+				parts = append(parts, partResult{Got: t})
+			}
+		}
+		return parts, true
+	}
+	if blanks := strings.Count(c.Prompt, "_____"); blanks < 2 || len(want) != blanks {
+		return nil, false
+	}
+	got := strings.Fields(ans)
+	level := strictnessFor(c)
+	for i, w := range want {
+		p := partResult{Want: w}
+		if i < len(got) {
+			p.Got = got[i]
+			p.OK = answerMatches(w, p.Got, level)
+		}
+		parts = append(parts, p)
+	}
+	return parts, true
+}
+
+func partScore(parts []partResult) float64 {
+	n := 0
+	for _, p := range parts {
+		if p.OK {
+			n++
+		}
+	}
+	return float64(n) / float64(len(parts))
+}
+
+// partsFeedback is the per-part line shown after a partly right answer.
+func partsFeedback(parts []partResult) string {
+	out := []string{}
+	for _, p := range parts {
+		switch {
+		case p.OK:
+			out = append(out, "✔ "+p.Want)
+		case p.Want == "":
+			out = append(out, "✘ extra "+p.Got)
+		case p.Got == "":
+			out = append(out, "✘ "+p.Want+" (missing)")
+		default:
+			out = append(out, fmt.Sprintf("✘ %s (you: %s)", p.Want, p.Got))
+		}
+	}
+	return strings.Join(out, "  ")
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxPatternLen keeps hand-written patterns readable; RE2 already rules out
+// catastrophic backtracking.
+const maxPatternLen = 200
+
+// compilePattern turns an answer pattern into an anchored regexp: /regex/ as
+// written, anything else as a glob where * is any run and ? one character.
+func compilePattern(p string) (*regexp.Regexp, error) {
+	if len(p) > maxPatternLen {
+		return nil, fmt.Errorf("answer pattern longer than %d characters", maxPatternLen)
+	}
+	expr := ""
+	if len(p) > 2 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+		expr = "^(?:" + p[1:len(p)-1] + ")$"
+	} else {
+		g := regexp.QuoteMeta(p)
+		g = strings.ReplaceAll(g, `\*`, ".*")
+		g = strings.ReplaceAll(g, `\?`, ".")
+		expr = "^" + g + "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("answer pattern %q: %w", p, err)
+	}
+	return re, nil
+}
+
+// matchPattern reports whether ans fits the card's answer pattern; a pattern
+// that doesn't compile matches nothing (memento validate reports it).
+func matchPattern(c Card, ans string) bool {
+	if c.AnswerPattern == "" {
+		return false
+	}
+	re, err := compilePattern(c.AnswerPattern)
+	return err == nil && re.MatchString(strings.TrimSpace(ans))
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"memento/pkg/srs"
+	"memento/pkg/storage"
+)
+
+// PauseState is a vacation: nothing is due between Since and Until, and on
+// resume every NextDue moves forward by the time actually paused.
+type PauseState struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+// paused is set by applyPause at startup while a pause is in effect.
+var paused *PauseState
+
+// DueCards is srs.DueCards, empty while paused.
+func DueCards(cards []Card, now time.Time) []Card {
+	if paused != nil {
+		return []Card{}
+	}
+	return srs.DueCards(cards, now)
+}
+
+func pausePath() (string, error) {
+	d, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "pause.json"), nil
+}
+
+func loadPause() (*PauseState, error) {
+	p, err := pausePath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st PauseState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return &st, nil
+}
+
+func savePause(st PauseState) error {
+	p, err := pausePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	logger.Info("pause", "since", st.Since, "until", st.Until)
+	return storage.WriteFileAtomic(p, b, 0o644)
+}
+
+// applyPause runs before every command: it ends a pause whose date has passed
+// (shifting the deck) or marks the deck as paused.
+func applyPause(now time.Time) error {
+	st, err := loadPause()
+	if err != nil || st == nil {
+		return err
+	}
+	if now.Before(st.Until) {
+		paused = st
+		return nil
+	}
+	_, _, err = resume(st.Until)
+	return err
+}
+
+// resume shifts every card by the part of the paused span ending at end that
+// it was in the deck for, and clears the pause, all under the store lock. ok
+// is false if another memento resumed first (pause.json is gone by the time
+// the lock is held).
+func resume(end time.Time) (shift time.Duration, ok bool, err error) {
+	p, err := pausePath()
+	if err != nil {
+		return 0, false, err
+	}
+	var st *PauseState
+	var ferr error
+	err = updateCards(func(cards []Card) []Card {
+		if st, ferr = loadPause(); ferr != nil || st == nil {
+			return nil
+		}
+		if ferr = os.Remove(p); ferr != nil {
+			return nil
+		}
+		shift = end.Sub(st.Since)
+		for i := range cards {
+			// a card added during the pause only waited since it was added
+			from := st.Since
+			if cards[i].Created.After(from) {
+				from = cards[i].Created
+			}
+			if d := end.Sub(from); d > 0 && !cards[i].NextDue.IsZero() {
+				cards[i].NextDue = cards[i].NextDue.Add(d)
+			}
+		}
+		return cards
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if ferr != nil || st == nil {
+		return 0, false, ferr
+	}
+	paused = nil
+	logger.Info("resume", "since", st.Since, "shift", shift.Round(time.Minute).String())
+	return shift, true, nil
+}
+
+func runPause(args []string) error {
+	fs := flag.NewFlagSet("pause", flag.ExitOnError)
+	until := fs.String("until", "", "resume on this date (2025-01-05)")
+	forSpan := fs.String("for", "", "pause for a span instead (10d, 2w, 1mo)")
+	_ = fs.Parse(args)
+	now := time.Now()
+	if *until == "" && *forSpan == "" {
+		if paused == nil {
+			fmt.Println("Not paused. Use memento pause --until 2025-01-05 (or --for 2w).")
+		} else {
+			fmt.Printf("Paused since %s, resuming %s (memento resume to end early).\n", paused.Since.Local().Format("2006-01-02"), paused.Until.Local().Format("2006-01-02"))
+		}
+		return nil
+	}
+	if paused != nil {
+		return fmt.Errorf("already paused until %s; memento resume first", paused.Until.Local().Format("2006-01-02"))
+	}
+	var end time.Time
+	var err error
+	switch {
+	case *until != "" && *forSpan != "":
+		return fmt.Errorf("use either --until or --for")
+	case *until != "":
+		end, err = time.ParseInLocation("2006-01-02", *until, time.Local)
+	default:
+		back, err := parseAge(*forSpan, now)
+		if err != nil {
+			return fmt.Errorf("--for: %w", err)
+		}
+		end = now.Add(now.Sub(back))
+	}
+	if err != nil {
+		return err
+	}
+	if !end.After(now) {
+		return fmt.Errorf("pause end %s is not in the future", end.Local().Format("2006-01-02"))
+	}
+	if err := savePause(PauseState{Since: now, Until: end}); err != nil {
+		return err
+	}
+	fmt.Printf("Paused until %s. Nothing comes due meanwhile; intervals resume where they left off.\n", end.Local().Format("Mon 2006-01-02"))
+	return nil
+}
+
+func runResume(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: memento resume")
+	}
+	if paused == nil {
+		fmt.Println("Not paused.")
+		return nil
+	}
+	shift, ok, err := resume(time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("Not paused (already resumed).")
+		return nil
+	}
+	fmt.Printf("Resumed; schedules moved forward by %s.\n", shift.Round(time.Hour))
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"memento/pkg/storage"
+)
+
+func TestParseAge(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		span string
+		want time.Time
+	}{
+		{"3d", now.AddDate(0, 0, -3)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"6mo", now.AddDate(0, -6, 0)},
+		{"1y", now.AddDate(-1, 0, 0)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		if got, err := parseAge(tt.span, now); err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", tt.span, got, err, tt.want)
+		}
+	}
+	for _, span := range []string{"xyz", "-3d", "-1w", "-12h", ""} {
+		_, err := parseAge(span, now)
+		if err == nil || strings.Contains(err.Error(), "--") {
+			t.Errorf("parseAge(%q): %v, want a flag-neutral error", span, err)
+		}
+	}
+}
+
+func TestResumeShift(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	defer func(s storage.Storage) { store = s }(store)
+	since := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	end := since.AddDate(0, 0, 10)
+	due := since.AddDate(0, 0, 2)
+	mem := storage.NewMemory([]Card{
+		{ID: "old", Created: since.AddDate(0, -1, 0), NextDue: due},
+		{ID: "mid", Created: since.AddDate(0, 0, 6), NextDue: due.AddDate(0, 0, 6)},
+		{ID: "new", Created: end.Add(time.Hour), NextDue: end.Add(time.Hour)},
+		{ID: "unscheduled", Created: since.AddDate(0, -1, 0)},
+	})
+	store = mem
+	if err := savePause(PauseState{Since: since, Until: end}); err != nil {
+		t.Fatal(err)
+	}
+	shift, ok, err := resume(end)
+	if err != nil || !ok || shift != 10*24*time.Hour {
+		t.Fatalf("resume = %v, %v, %v", shift, ok, err)
+	}
+	deck, err := mem.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]time.Time{
+		"old":         due.AddDate(0, 0, 10),
+		"mid":         due.AddDate(0, 0, 6+4),
+		"new":         end.Add(time.Hour),
+		"unscheduled": {},
+	}
+	for _, c := range deck {
+		if !c.NextDue.Equal(want[c.ID]) {
+			t.Errorf("%s: due %v, want %v", c.ID, c.NextDue, want[c.ID])
+		}
+	}
+	if _, ok, err := resume(end); ok || err != nil {
+		t.Errorf("second resume = %v, %v; want not paused", ok, err)
+	}
+}
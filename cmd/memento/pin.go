@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+func runPin(args []string) error {
+	fs := flag.NewFlagSet("pin", flag.ExitOnError)
+	off := fs.Bool("off", false, "unpin the card")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		if *off {
+			return fmt.Errorf("usage: memento pin --off <id-prefix>")
+		}
+		cards, err := LoadCards()
+		if err != nil {
+			return err
+		}
+		n := 0
+		for _, c := range cards {
+			if c.Pinned {
+				printCardRow(c)
+				n++
+			}
+		}
+		if n == 0 {
+			fmt.Println("No pinned cards. Pin one with memento pin <id> (or p in memento browse).")
+		}
+		return nil
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: memento pin [--off] <id-prefix>")
+	}
+	var c Card
+	var ferr error
+	changed := false
+	err := updateCards(func(cards []Card) []Card {
+		i, err := findCard(cards, fs.Arg(0))
+		if err != nil {
+			ferr = err
+			return nil
+		}
+		if c = cards[i]; c.Pinned == !*off {
+			return nil
+		}
+		cards[i].Pinned = !*off
+		c, changed = cards[i], true
+		return cards
+	})
+	if err == nil {
+		err = ferr
+	}
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Println("Unchanged:", cardRow(c, time.Now()))
+		return nil
+	}
+	if c.Suspended && c.Pinned {
+		fmt.Println("Note: the card is suspended; it won't come up until unsuspended.")
+	}
+	printCardRow(c)
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// Pipeline-ordering cards: the stages of a piped one-liner are shown shuffled
+// and the answer is their correct order, e.g. "3 1 2".
+
+const (
+	pipelineMinStages = 3
+)
+
+func pipelineStages(cmd string) []string {
+	if strings.Contains(cmd, "||") {
+		return nil
+	}
+	parts := strings.Split(cmd, "|")
+	stages := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p == "" {
+			return nil
+		}
+		stages = append(stages, p)
+	}
+	return stages
+}
+
+// pipelineCard shuffles stages deterministically (seeded by the card ID) so
+// re-ingesting yields the same prompt.
+func pipelineCard(canon string, now time.Time) (Card, bool) {
+	stages := pipelineStages(canon)
+	if len(stages) < pipelineMinStages {
+		return Card{}, false
+	}
+	id := hash("pipe:" + canon)
+	seed, _ := strconv.ParseInt(id[:15], 16, 64)
+	r := rand.New(rand.NewSource(seed))
+	perm := r.Perm(len(stages))
+	for identity(perm) {
+		perm = r.Perm(len(stages))
+	}
+
+	// perm[i] = original position of the stage shown at slot i
+	var prompt strings.Builder
+	prompt.WriteString("Order these pipeline stages:")
+	pos := make([]int, len(stages))
+	for slot, orig := range perm {
+		fmt.Fprintf(&prompt, "\n  %d) %s", slot+1, stages[orig])
+		pos[orig] = slot + 1
+	}
+	order := make([]string, len(pos))
+	for i, p := range pos {
+		order[i] = strconv.Itoa(p)
+	}
+	return Card{
+		ID: id, Type: cards.Order, Prompt: prompt.String(), Answer: strings.Join(order, " "),
+		Hint: "Type the stage numbers in pipeline order, e.g. 2 3 1", Command: canon,
+		Tags: unique(append(deriveTags(canon), pipelineTag)), Box: 1, NextDue: now, SeenCount: 1,
+	}, true
+}
+
+func identity(p []int) bool {
+	for i, v := range p {
+		if i != v {
+			return false
+		}
+	}
+	return true
+}
+
+func GeneratePipelineCards(events []CommandEvent, existing []Card, now time.Time) []Card {
+	have := map[string]bool{}
+	for _, c := range existing {
+		have[c.ID] = true
+	}
+	out := []Card{}
+	for _, ev := range events {
+		c, ok := pipelineCard(ev.Command, now)
+		if !ok || have[c.ID] {
+			continue
+		}
+		have[c.ID] = true
+		c.Origins = mergeOrigin(nil, ev.Origin)
+		out = append(out, c)
+	}
+	return out
+}
+
+// checkOrder compares stage numbers, ignoring separators ("231", "2 3 1", "2,3,1").
+func checkOrder(want, ans string) bool {
+	digits := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, s)
+	}
+	return digits(ans) != "" && digits(ans) == digits(want)
+}
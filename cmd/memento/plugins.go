@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Normalizer plugins: an executable named memento-normalize-<tool> on PATH
+// gets one (scrubbed) command line on stdin and answers with JSON:
+//
+//	{"masked": "acmectl deploy --env <ENV> <SVC>", "targets": ["deploy", "--env"], "hint": "..."}
+//
+// masked replaces the built-in normalization for that tool; targets are cloze
+// candidates in order of preference (tokens of masked); hint is optional.
+// A plugin that fails, times out or prints bad JSON is ignored for the run.
+const normalizerPrefix = "memento-normalize-"
+
+const pluginTimeout = 2 * time.Second
+
+type pluginResult struct {
+	Masked  string   `json:"masked"`
+	Targets []string `json:"targets,omitempty"`
+	Hint    string   `json:"hint,omitempty"`
+}
+
+var (
+	pluginPaths   = map[string]string{}        // tool → executable ("" = none)
+	pluginResults = map[string]*pluginResult{} // input or masked output → result
+)
+
+func normalizerPlugin(tool string) string {
+	if p, hit := pluginPaths[tool]; hit {
+		return p
+	}
+	p, err := exec.LookPath(normalizerPrefix + tool)
+	if err != nil {
+		p = ""
+	}
+	pluginPaths[tool] = p
+	return p
+}
+
+// pluginNormalize runs the tool's plugin, if any. Results are memoized under
+// both the input and the masked output, so normalizing twice is stable.
+func pluginNormalize(cmd string) (*pluginResult, bool) {
+	if r, hit := pluginResults[cmd]; hit {
+		return r, r != nil
+	}
+	f := strings.Fields(cmd)
+	if len(f) == 0 {
+		return nil, false
+	}
+	p := normalizerPlugin(f[0])
+	if p == "" {
+		return nil, false
+	}
+	r, err := callPlugin(p, cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s: %v (using built-in normalizer)\n", p, err)
+		pluginPaths[f[0]] = ""
+		return nil, false
+	}
+	pluginResults[cmd] = r
+	pluginResults[r.Masked] = r
+	return r, true
+}
+
+func callPlugin(path, cmd string) (*pluginResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+	c := exec.CommandContext(ctx, path)
+	c.Stdin = strings.NewReader(cmd + "\n")
+	var out bytes.Buffer
+	c.Stdout = &out
+	if err := c.Run(); err != nil {
+		return nil, err
+	}
+	var r pluginResult
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil {
+		return nil, fmt.Errorf("bad JSON: %w", err)
+	}
+	r.Masked = strings.Join(strings.Fields(r.Masked), " ")
+	if r.Masked == "" {
+		return nil, fmt.Errorf("empty \"masked\"")
+	}
+	return &r, nil
+}
+
+// pluginCloze blanks the first plugin target present in cmd.
+func pluginCloze(cmd string) (prompt, answer, hint string, ok bool) {
+	r, ok := pluginResults[cmd]
+	if !ok || r == nil {
+		return "", "", "", false
+	}
+	words := strings.Fields(cmd)
+	for _, t := range r.Targets {
+		for i, w := range words {
+			if w != t {
+				continue
+			}
+			masked := append([]string{}, words...)
+			masked[i] = "_____"
+			hint = r.Hint
+			if hint == "" {
+				hint = "Type the missing flag/subcommand"
+			}
+			return strings.Join(masked, " "), w, hint, true
+		}
+	}
+	return "", "", "", false
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// practicePass is the token overlap that counts as "got it" in the arena.
+const practicePass = 0.6
+
+const genericHint = "Type the missing flag/subcommand"
+
+// practicePrompt shows only the goal and the tool: the card hint when it says
+// something, otherwise the card's non-tool tags.
+func practicePrompt(c Card) string {
+	tool := toolOf(c)
+	goal := c.Hint
+	if goal == "" || goal == genericHint {
+		tags := []string{}
+		for _, t := range c.Tags {
+			if t != tool {
+				tags = append(tags, t)
+			}
+		}
+		goal = "something you ran with " + tool
+		if len(tags) > 0 {
+			goal += " (" + strings.Join(tags, ", ") + ")"
+		}
+	}
+	return fmt.Sprintf("Goal: %s\nTool: %s", goal, tool)
+}
+
+// practiceScore compares token sets after normalizing the attempt the same way
+// the card's command was, so paths and numbers don't count against you.
+func practiceScore(c Card, ans string) float64 {
+	if strings.TrimSpace(ans) == "" {
+		return 0
+	}
+	return tokenOverlap(c.Command, normalizeCommand(ans))
+}
+
+func practiceFeedback(score float64, target string) string {
+	mark := "✘"
+	if score >= practicePass {
+		mark = "✔"
+	}
+	return fmt.Sprintf("%s %.0f%% match → %s", mark, 100*score, target)
+}
+
+// practiceFill is a card's command with its placeholders filled in
+// (practice --concrete).
+type practiceFill struct {
+	cmd  string
+	vals []string
+}
+
+// typed is the share of the sample values that appear verbatim in ans.
+func (f practiceFill) typed(ans string) float64 {
+	toks := set(strings.Fields(ans)...)
+	n := 0
+	for _, v := range f.vals {
+		if toks[v] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(f.vals))
+}
+
+// sampleValues collects the concrete values the stored examples used for each
+// placeholder, across all cards.
+func sampleValues(cards []Card) map[string][]string {
+	out := map[string][]string{}
+	seen := map[string]bool{}
+	for _, c := range cards {
+		phs, vals := exampleSamples(c.Example)
+		for i, ph := range phs {
+			if !seen[ph+" "+vals[i]] {
+				seen[ph+" "+vals[i]] = true
+				out[ph] = append(out[ph], vals[i])
+			}
+		}
+	}
+	return out
+}
+
+// fillPlaceholders swaps each placeholder in c.Command for a random sample;
+// ok is false when there is nothing to fill or a placeholder has no samples.
+func fillPlaceholders(c Card, samples map[string][]string, r *rand.Rand) (practiceFill, bool) {
+	toks := strings.Fields(c.Command)
+	f := practiceFill{}
+	for i, t := range toks {
+		if !placeholderRe.MatchString(t) {
+			continue
+		}
+		vs := samples[t]
+		if len(vs) == 0 {
+			return practiceFill{}, false
+		}
+		toks[i] = vs[r.Intn(len(vs))]
+		f.vals = append(f.vals, toks[i])
+	}
+	f.cmd = strings.Join(toks, " ")
+	return f, len(f.vals) > 0
+}
+
+func runPractice(args []string) error {
+	fs := flag.NewFlagSet("practice", flag.ExitOnError)
+	query := fs.String("query", "", `practice commands matching a query, e.g. "tool:kubectl"`)
+	count := fs.Int("count", 10, "commands per round")
+	concrete := fs.Bool("concrete", false, "fill placeholders with sample values from your history and type the command as you'd run it")
+	_ = fs.Parse(args)
+	q, err := ParseQuery(*query)
+	if err != nil {
+		return err
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	all, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	seen := map[string]bool{}
+	pool := filterCards(all, func(c Card) bool {
+		ok := c.Command != "" && c.Kind() == "cloze" && !seen[c.Command] && q.Match(c, now)
+		seen[c.Command] = seen[c.Command] || ok
+		return ok
+	})
+	fills := map[string]practiceFill{}
+	if *concrete {
+		samples := sampleValues(all)
+		r := rand.New(rand.NewSource(now.UnixNano()))
+		pool = filterCards(pool, func(c Card) bool {
+			f, ok := fillPlaceholders(c, samples, r)
+			fills[c.ID] = f
+			return ok
+		})
+	}
+	rand.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	if len(pool) > *count {
+		pool = pool[:*count]
+	}
+	m := initialModel(pool, all, cfg)
+	m.practice = true
+	if *concrete {
+		m.concrete = fills
+	}
+	if len(pool) > 0 {
+		m.setSuggestions()
+		m.pickInput()
+	}
+	final, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return err
+	}
+	if scores := final.(model).scores; len(scores) > 0 {
+		sum, passed := 0.0, 0
+		for _, s := range scores {
+			sum += s
+			if s >= practicePass {
+				passed++
+			}
+		}
+		fmt.Printf("Practised %d commands: %d passed, average match %.0f%%\n", len(scores), passed, 100*sum/float64(len(scores)))
+	}
+	return nil
+}
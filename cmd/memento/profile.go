@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+	"memento/pkg/ingest"
+)
+
+// stopProfiling writes out whatever profiles are running; fatal calls it too.
+var stopProfiling = func() {}
+
+// profileFlags removes the hidden --cpuprofile/--memprofile flags (with
+// "=value" or a separate value) from args wherever they appear.
+func profileFlags(args []string) (out []string, cpu, mem string) {
+	out = []string{}
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		for _, f := range []struct {
+			name string
+			dst  *string
+		}{{"--cpuprofile", &cpu}, {"--memprofile", &mem}} {
+			if v, ok := strings.CutPrefix(a, f.name+"="); ok {
+				*f.dst, a = v, ""
+			} else if a == f.name && i+1 < len(args) {
+				*f.dst, a = args[i+1], ""
+				i++
+			}
+		}
+		if a != "" {
+			out = append(out, a)
+		}
+	}
+	return out, cpu, mem
+}
+
+// startProfiling starts a CPU profile and arranges for a heap profile at
+// stopProfiling. Either file may be empty.
+func startProfiling(cpu, mem string) error {
+	var cf *os.File
+	if cpu != "" {
+		f, err := os.Create(cpu)
+		if err != nil {
+			return err
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return err
+		}
+		cf = f
+	}
+	stopProfiling = func() {
+		stopProfiling = func() {}
+		if cf != nil {
+			pprof.StopCPUProfile()
+			cf.Close()
+		}
+		if mem != "" {
+			f, err := os.Create(mem)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "memprofile:", err)
+				return
+			}
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				fmt.Fprintln(os.Stderr, "memprofile:", err)
+			}
+			f.Close()
+		}
+	}
+	return nil
+}
+
+// runBench times the ingest pipeline over one history file without touching
+// the deck: reading, scrubbing, normalizing, and full card generation.
+func runBench(args []string) error {
+	if len(args) == 0 || args[0] != "ingest" {
+		return errors.New("usage: memento bench ingest --file f [--shell zsh|bash|fish] [--runs 5]")
+	}
+	fs := flag.NewFlagSet("bench ingest", flag.ExitOnError)
+	file := fs.String("file", "", "history file to parse (required)")
+	shell := fs.String("shell", "", "parser for --file: zsh|bash|fish (default: sniff)")
+	runs := fs.Int("runs", 5, "repetitions; the fastest and the mean are reported")
+	_ = fs.Parse(args[1:])
+	if *file == "" {
+		return errors.New("--file is required")
+	}
+	if *runs < 1 {
+		return errors.New("--runs must be at least 1")
+	}
+	if _, err := os.Stat(*file); err != nil {
+		return err
+	}
+	sh := *shell
+	if sh == "" {
+		sh = ingest.SniffShell(*file)
+	}
+	srcs := []ingest.Source{{Path: *file, Shell: sh}}
+
+	var raws, scrubbed []string
+	phases := []struct {
+		name string
+		run  func()
+	}{
+		{"read", func() {
+			raws = raws[:0]
+			ingest.Walk(srcs, func(raw string, _ time.Time, _ cards.Origin) { raws = append(raws, raw) })
+		}},
+		{"scrub", func() {
+			scrubbed = scrubbed[:0]
+			for _, r := range raws {
+				if s := scrub(r); !isIgnorable(s) {
+					scrubbed = append(scrubbed, s)
+				}
+			}
+		}},
+		{"normalize", func() {
+			for _, s := range scrubbed {
+				normalizeCommand(s)
+			}
+		}},
+		{"generate", func() { generateAll(srcs, ingest.Window{}, nil, time.Now()) }},
+	}
+	best := make([]time.Duration, len(phases))
+	total := make([]time.Duration, len(phases))
+	for range *runs {
+		for i, p := range phases {
+			start := time.Now()
+			p.run()
+			d := time.Since(start)
+			total[i] += d
+			if best[i] == 0 || d < best[i] {
+				best[i] = d
+			}
+		}
+	}
+	fmt.Printf("%s (%s): %d lines, %d kept after scrub, %d runs\n", *file, sh, len(raws), len(scrubbed), *runs)
+	fmt.Printf("%-10s %12s %12s %12s\n", "phase", "best", "mean", "per line")
+	for i, p := range phases {
+		perLine := time.Duration(0)
+		if len(raws) > 0 {
+			perLine = best[i] / time.Duration(len(raws))
+		}
+		fmt.Printf("%-10s %12s %12s %12s\n", p.name, best[i].Round(time.Microsecond), (total[i] / time.Duration(*runs)).Round(time.Microsecond), perLine)
+	}
+	fmt.Println("generate is the whole pipeline (read included), as memento ingest runs it.")
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"memento/pkg/ingest"
+)
+
+// ingestMeter shows ingest progress; nil (not a terminal, or --quiet) shows nothing.
+var ingestMeter *meter
+
+// meter is a one-line progress bar: bytes of history read, the file being
+// read, lines per second and cards generated so far.
+type meter struct {
+	w            io.Writer
+	files        []string
+	sizes        []int64
+	size, read   int64 // read is approximate: line lengths, resynced at each new file
+	file         int   // 1-based index of the file being read
+	cur          string
+	lines, cards int
+	start, drawn time.Time
+	reading      time.Duration // set once cards are being generated: lines/s stops there
+}
+
+func newMeter(w io.Writer, srcs []ingest.Source) *meter {
+	m := &meter{w: w, start: time.Now()}
+	for _, s := range srcs {
+		var n int64
+		if fi, err := os.Stat(s.Path); err == nil {
+			n = fi.Size()
+		}
+		m.files, m.sizes, m.size = append(m.files, s.Path), append(m.sizes, n), m.size+n
+	}
+	return m
+}
+
+// line counts one history line read from file.
+func (m *meter) line(file, raw string) {
+	if m == nil {
+		return
+	}
+	if file != m.cur {
+		m.cur, m.read = file, 0
+		for i, f := range m.files {
+			if f == file {
+				m.file = i + 1
+				break
+			}
+			m.read += m.sizes[i]
+		}
+	}
+	m.lines++
+	m.read += int64(len(raw)) + 1
+	m.draw()
+}
+
+// found sets the number of cards generated so far.
+func (m *meter) found(n int) {
+	if m == nil {
+		return
+	}
+	if m.reading == 0 {
+		m.reading = time.Since(m.start)
+	}
+	m.cards = n
+	m.draw()
+}
+
+func (m *meter) draw() {
+	now := time.Now()
+	if now.Sub(m.drawn) < 100*time.Millisecond {
+		return
+	}
+	m.drawn = now
+	frac := 1.0
+	if m.size > 0 {
+		frac = min(float64(m.read)/float64(m.size), 1)
+	}
+	const width = 20
+	n := int(frac * width)
+	took := m.reading
+	if took == 0 {
+		took = now.Sub(m.start)
+	}
+	rate := float64(m.lines) / max(took.Seconds(), 0.001)
+	fmt.Fprintf(m.w, "\r\033[K[%s%s] %3.0f%%  file %d/%d  %d lines  %.0f lines/s  %d cards",
+		strings.Repeat("█", n), strings.Repeat("░", width-n), 100*frac, m.file, len(m.files), m.lines, rate, m.cards)
+}
+
+// done clears the bar.
+func (m *meter) done() {
+	if m == nil {
+		return
+	}
+	fmt.Fprint(m.w, "\r\033[K")
+}
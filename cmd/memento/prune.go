@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os/exec"
+	"slices"
+)
+
+// toolCache memoizes exec.LookPath per process; decks can hold hundreds of cards per tool.
+var toolCache = map[string]bool{}
+
+func toolInstalled(tool string) bool {
+	if ok, hit := toolCache[tool]; hit {
+		return ok
+	}
+	_, err := exec.LookPath(tool)
+	toolCache[tool] = err == nil
+	return err == nil
+}
+
+// ToolMissing reports whether the card's tool can't be found on PATH.
+// Cards without a recognisable tool (imported notes, placeholders) are kept.
+func ToolMissing(c Card) bool {
+	tool := toolOf(c)
+	if tool == "misc" || tool == "" || tool[0] == '<' || tool[0] == '-' {
+		return false
+	}
+	return !toolInstalled(tool)
+}
+
+func runPrune(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	missing := fs.Bool("missing-tools", false, "delete cards for tools not installed on this machine")
+	dryRun := fs.Bool("dry-run", false, "only list what would be deleted")
+	_ = fs.Parse(args)
+	if !*missing {
+		return fmt.Errorf("usage: memento prune --missing-tools [--dry-run]")
+	}
+
+	pruned, total := 0, 0
+	err := saveUndoable(undoEntry{What: "prune --missing-tools"}, func(cards []Card) []Card {
+		gone := map[string]int{}
+		keep := slices.DeleteFunc(cards, func(c Card) bool {
+			if ToolMissing(c) {
+				gone[toolOf(c)]++
+				return true
+			}
+			return false
+		})
+		for tool, n := range gone {
+			fmt.Printf("  %-16s %d cards\n", tool, n)
+		}
+		pruned, total = len(cards)-len(keep), len(keep)
+		if *dryRun {
+			return nil
+		}
+		return keep
+	})
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Printf("Would prune %d cards.\n", pruned)
+		return nil
+	}
+	fmt.Printf("Pruned %d cards. Total: %d\n", pruned, total)
+	return nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"memento/pkg/cards"
+)
+
+// Query is a parsed card filter such as `tag:git box:<3 due:today seen:>5 "rebase"`.
+// Terms are ANDed; a leading '-' negates a term; bare words and quoted
+// strings match prompt, answer, command and notes (case-insensitive).
+type Query struct {
+	Src   string
+	terms []queryTerm
+}
+
+type queryTerm struct {
+	neg   bool
+	field string // "" = free text
+	op    string // "=", "<", "<=", ">", ">="
+	value string
+}
+
+// QueryError points at the offending column of the query source.
+type QueryError struct {
+	Src string
+	Pos int
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query: %s (col %d)\n  %s\n  %s^", e.Msg, e.Pos+1, e.Src, strings.Repeat(" ", e.Pos))
+}
+
+var queryFields = []struct{ name, help string }{
+	{"tag", "has tag (exact)"},
+	{"kind", "cloze|sequence|pipeline|comprehension|danger|combo"},
+	{"type", "cloze|reverse|full-recall|sequence|comprehension|multiple-choice|order"},
+	{"tool", "first word of the command"},
+	{"host", "seen on host"},
+	{"id", "ID prefix"},
+	{"box", "Leitner box, e.g. box:<3"},
+	{"seen", "times seen in history, e.g. seen:>5"},
+	{"reviews", "times reviewed"},
+	{"due", "today|tomorrow|overdue, a date, or a span from now like due:<7d"},
+	{"is", "suspended|new|pinned|starred"},
+}
+
+func queryField(name string) (string, bool) {
+	for _, f := range queryFields {
+		if f.name == name {
+			return f.help, true
+		}
+	}
+	return "", false
+}
+
+func queryFieldNames() string {
+	names := []string{}
+	for _, f := range queryFields {
+		names = append(names, f.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParseQuery tokenizes and validates a query. An empty query matches everything.
+func ParseQuery(src string) (Query, error) {
+	q := Query{Src: src}
+	rs := []rune(src)
+	fail := func(pos int, format string, a ...any) (Query, error) {
+		return Query{}, &QueryError{Src: src, Pos: pos, Msg: fmt.Sprintf(format, a...)}
+	}
+	quoted := func(i int) (string, int, bool) { // rs[i] == '"'
+		var b strings.Builder
+		for j := i + 1; j < len(rs); j++ {
+			switch {
+			case rs[j] == '\\' && j+1 < len(rs):
+				j++
+				b.WriteRune(rs[j])
+			case rs[j] == '"':
+				return b.String(), j + 1, true
+			default:
+				b.WriteRune(rs[j])
+			}
+		}
+		return "", len(rs), false
+	}
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) {
+			i++
+			continue
+		}
+		t := queryTerm{op: "="}
+		if rs[i] == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) {
+			t.neg = true
+			i++
+		}
+		if rs[i] == '"' {
+			s, next, ok := quoted(i)
+			if !ok {
+				return fail(i, "unterminated quote")
+			}
+			t.value, i = s, next
+			q.terms = append(q.terms, t)
+			continue
+		}
+		start := i
+		for i < len(rs) && !unicode.IsSpace(rs[i]) && rs[i] != ':' && rs[i] != '"' {
+			i++
+		}
+		word := string(rs[start:i])
+		if i >= len(rs) || rs[i] != ':' {
+			if i < len(rs) && rs[i] == '"' {
+				return fail(i, "unexpected quote inside %q (quote the whole term)", word)
+			}
+			t.value = word
+			q.terms = append(q.terms, t)
+			continue
+		}
+		t.field = strings.ToLower(word)
+		help, ok := queryField(t.field)
+		if !ok {
+			return fail(start, "unknown field %q (fields: %s)", word, queryFieldNames())
+		}
+		i++ // ':'
+		vpos := i
+		for _, op := range []string{"<=", ">=", "<", ">", "="} {
+			if strings.HasPrefix(string(rs[i:]), op) {
+				t.op = op
+				i += len([]rune(op))
+				break
+			}
+		}
+		if i < len(rs) && rs[i] == '"' {
+			s, next, ok := quoted(i)
+			if !ok {
+				return fail(i, "unterminated quote")
+			}
+			t.value, i = s, next
+		} else {
+			vs := i
+			for i < len(rs) && !unicode.IsSpace(rs[i]) {
+				i++
+			}
+			t.value = string(rs[vs:i])
+		}
+		if t.value == "" {
+			return fail(vpos, "%s: needs a value (%s)", t.field, help)
+		}
+		if err := t.validate(); err != nil {
+			return fail(vpos, "%s", err)
+		}
+		q.terms = append(q.terms, t)
+	}
+	return q, nil
+}
+
+func (t queryTerm) validate() error {
+	switch t.field {
+	case "box", "seen", "reviews":
+		if _, err := strconv.Atoi(t.value); err != nil {
+			return fmt.Errorf("%s wants a number, got %q", t.field, t.value)
+		}
+	case "due":
+		if _, err := dueBound(t.value, time.Now()); err != nil {
+			return err
+		}
+	case "is":
+		switch t.value {
+		case "suspended", "new", "pinned", "starred":
+		default:
+			return fmt.Errorf("is %q: want suspended, new, pinned or starred", t.value)
+		}
+	case "kind":
+		switch t.value {
+		case "cloze", sequenceTag, pipelineTag, comprehensionTag, dangerTag, comboTag:
+		default:
+			return fmt.Errorf("kind %q: want cloze, sequence, pipeline, comprehension, danger or combo", t.value)
+		}
+	case "type":
+		if _, err := cards.ParseType(t.value); err != nil {
+			return err
+		}
+	default:
+		if t.op != "=" {
+			return fmt.Errorf("%s does not take %s", t.field, t.op)
+		}
+	}
+	return nil
+}
+
+// dueBound turns a due: value into a point in time relative to now.
+func dueBound(v string, now time.Time) (time.Time, error) {
+	today := dayStart(now)
+	switch v {
+	case "now", "overdue":
+		return now, nil
+	case "today":
+		return today.AddDate(0, 0, 1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 2), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", v, time.Local); err == nil {
+		return t, nil
+	}
+	past, err := parseAge(v, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("due %q: want today, tomorrow, overdue, a date or a span like 7d", v)
+	}
+	return now.Add(now.Sub(past)), nil
+}
+
+func (q Query) Empty() bool { return len(q.terms) == 0 }
+
+func (q Query) Match(c Card, now time.Time) bool {
+	for _, t := range q.terms {
+		if t.match(c, now) == t.neg {
+			return false
+		}
+	}
+	return true
+}
+
+func (t queryTerm) match(c Card, now time.Time) bool {
+	switch t.field {
+	case "":
+		v := strings.ToLower(t.value)
+		for _, s := range []string{c.Prompt, c.Answer, c.Command, c.Notes} {
+			if strings.Contains(strings.ToLower(s), v) {
+				return true
+			}
+		}
+		return false
+	case "tag":
+		for _, tag := range c.Tags {
+			if strings.EqualFold(tag, t.value) {
+				return true
+			}
+		}
+		return false
+	case "kind":
+		return c.Kind() == t.value
+	case "type":
+		return string(c.TypeOf()) == t.value
+	case "is":
+		switch t.value {
+		case "suspended":
+			return c.Suspended
+		case "new":
+			return c.TimesSeen == 0
+		case "pinned":
+			return c.Pinned
+		case "starred":
+			return c.Starred
+		}
+		return false
+	case "tool":
+		return strings.EqualFold(toolOf(c), t.value)
+	case "host":
+		return c.FromHost(t.value)
+	case "id":
+		return strings.HasPrefix(c.ID, t.value)
+	case "box":
+		return compareInt(c.Box, t.op, t.value)
+	case "seen":
+		return compareInt(c.SeenCount, t.op, t.value)
+	case "reviews":
+		return compareInt(c.TimesSeen, t.op, t.value)
+	case "due":
+		b, _ := dueBound(t.value, now)
+		switch t.op {
+		case ">":
+			return c.NextDue.After(b)
+		case ">=":
+			return !c.NextDue.Before(b)
+		case "<=":
+			return !c.NextDue.After(b)
+		}
+		return c.NextDue.Before(b) // due:today = due by the end of today
+	}
+	return false
+}
+
+func compareInt(n int, op, v string) bool {
+	m, _ := strconv.Atoi(v)
+	switch op {
+	case "<":
+		return n < m
+	case "<=":
+		return n <= m
+	case ">":
+		return n > m
+	case ">=":
+		return n >= m
+	}
+	return n == m
+}
+
+// queryArg parses the positional args of a subcommand as one query.
+func queryArg(args []string) (Query, error) {
+	return ParseQuery(strings.Join(args, " "))
+}
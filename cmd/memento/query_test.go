@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []queryTerm
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"rebase", []queryTerm{{op: "=", value: "rebase"}}},
+		{"-rebase", []queryTerm{{neg: true, op: "=", value: "rebase"}}},
+		{"a - b", []queryTerm{{op: "=", value: "a"}, {op: "=", value: "-"}, {op: "=", value: "b"}}},
+		{`"git rebase -i"`, []queryTerm{{op: "=", value: "git rebase -i"}}},
+		{`-"force push"`, []queryTerm{{neg: true, op: "=", value: "force push"}}},
+		{`"say \"hi\" \\ now"`, []queryTerm{{op: "=", value: `say "hi" \ now`}}},
+		{"tag:git", []queryTerm{{field: "tag", op: "=", value: "git"}}},
+		{"TAG:git", []queryTerm{{field: "tag", op: "=", value: "git"}}},
+		{"-tag:git", []queryTerm{{neg: true, field: "tag", op: "=", value: "git"}}},
+		{`tag:"two words"`, []queryTerm{{field: "tag", op: "=", value: "two words"}}},
+		{"box:<3", []queryTerm{{field: "box", op: "<", value: "3"}}},
+		{"box:<=3", []queryTerm{{field: "box", op: "<=", value: "3"}}},
+		{"seen:>5", []queryTerm{{field: "seen", op: ">", value: "5"}}},
+		{"reviews:>=2", []queryTerm{{field: "reviews", op: ">=", value: "2"}}},
+		{"box:=2", []queryTerm{{field: "box", op: "=", value: "2"}}},
+		{"due:<7d", []queryTerm{{field: "due", op: "<", value: "7d"}}},
+		{"due:2024-01-31", []queryTerm{{field: "due", op: "=", value: "2024-01-31"}}},
+		{"tool:tar is:new -is:suspended", []queryTerm{
+			{field: "tool", op: "=", value: "tar"},
+			{field: "is", op: "=", value: "new"},
+			{neg: true, field: "is", op: "=", value: "suspended"},
+		}},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.src)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.src, err)
+			continue
+		}
+		if q.Src != tt.src || !reflect.DeepEqual(q.terms, tt.want) {
+			t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.src, q.terms, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+		msg string
+	}{
+		{`"open`, 0, "unterminated quote"},
+		{`tag:git "open`, 8, "unterminated quote"},
+		{`tag:"open`, 4, "unterminated quote"},
+		{`-"open`, 1, "unterminated quote"},
+		{`ab"cd"`, 2, "unexpected quote"},
+		{"colour:red", 0, "unknown field"},
+		{"rebase nope:x", 7, "unknown field"},
+		{"tag:", 4, "needs a value"},
+		{"box:<", 4, "needs a value"},
+		{"box:many", 4, "wants a number"},
+		{"seen:>x", 5, "wants a number"},
+		{"is:boring", 3, "want suspended"},
+		{"kind:flashcard", 5, "kind"},
+		{"type:essay", 5, ""},
+		{"due:someday", 4, "due"},
+		{"tag:<git", 4, "does not take <"},
+		{"host:>=a", 5, "does not take >="},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.src)
+		var qe *QueryError
+		if !errors.As(err, &qe) {
+			t.Errorf("ParseQuery(%q): got %v, want a QueryError", tt.src, err)
+			continue
+		}
+		if qe.Pos != tt.pos || !strings.Contains(qe.Msg, tt.msg) {
+			t.Errorf("ParseQuery(%q): col %d %q, want col %d containing %q", tt.src, qe.Pos, qe.Msg, tt.pos, tt.msg)
+		}
+		caret := strings.Split(qe.Error(), "\n")[2]
+		if want := "  " + strings.Repeat(" ", tt.pos) + "^"; caret != want {
+			t.Errorf("ParseQuery(%q): caret line %q, want %q", tt.src, caret, want)
+		}
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.Local)
+	c := Card{
+		ID:        "abc123",
+		Prompt:    "Rebase onto main",
+		Answer:    "git rebase -i main",
+		Command:   "git rebase -i main",
+		Notes:     "Squash Fixups",
+		Tags:      []string{"git", "vcs"},
+		Box:       2,
+		SeenCount: 7,
+		TimesSeen: 3,
+		NextDue:   now.Add(2 * time.Hour),
+		Pinned:    true,
+	}
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"", true},
+		{"REBASE", true},
+		{"fixups", true},
+		{"-rebase", false},
+		{`"rebase -i"`, true},
+		{`"rebase  -i"`, false},
+		{"tag:GIT", true},
+		{"tag:gi", false},
+		{"-tag:docker", true},
+		{"tool:git", true},
+		{"id:abc", true},
+		{"id:bc", false},
+		{"box:2", true},
+		{"box:<2", false},
+		{"box:<=2", true},
+		{"seen:>5", true},
+		{"seen:>=8", false},
+		{"reviews:3", true},
+		{"due:today", true},
+		{"due:overdue", false},
+		{"due:>now", true},
+		{"is:pinned", true},
+		{"is:new", false},
+		{"is:suspended", false},
+		{"-is:starred", true},
+		{"tag:git box:2 seen:>5", true},
+		{"tag:git box:3", false},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.src)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.src, err)
+			continue
+		}
+		if got := q.Match(c, now); got != tt.want {
+			t.Errorf("%q matched %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
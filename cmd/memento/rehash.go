@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// commandID is the ID a generator gives a card of kind for canonical command
+// cmd; "" for kinds whose IDs don't come from the command alone.
+func commandID(kind, cmd string) string {
+	switch kind {
+	case "cloze":
+		return hash(cmd)
+	case pipelineTag:
+		return hash("pipe:" + cmd)
+	case dangerTag:
+		return hash("danger:" + cmd)
+	case comprehensionTag:
+		return hash("comp:" + cmd)
+	case comboTag:
+		return hash("combo:" + cmd)
+	}
+	return ""
+}
+
+// rehashCard re-normalizes c from its raw example (or its command) and
+// returns the card under its new ID, content regenerated and progress kept.
+// ok is false when c's ID isn't one normalizeCommand produced.
+func rehashCard(c Card, now time.Time) (Card, bool) {
+	kind := c.Kind()
+	if c.Command == "" || commandID(kind, c.Command) != c.ID {
+		return c, false // anki, deck, discover, sequence, tutorial…
+	}
+	raw := c.Example
+	if raw == "" {
+		raw = c.Command
+	}
+	canon := normalizeCommand(raw)
+	if canon == c.Command {
+		return c, true
+	}
+	n := c
+	n.ID, n.Command = commandID(kind, canon), canon
+	n.Example = exampleOf(canon, raw)
+	n.Samples = cardSamples(n.Example)
+	switch kind {
+	case "cloze":
+		n.Prompt, n.Answer, n.Hint = cloze(canon)
+	case pipelineTag:
+		if p, ok := pipelineCard(canon, now); ok {
+			n.Prompt, n.Answer, n.Hint = p.Prompt, p.Answer, p.Hint
+		}
+	case comboTag:
+		if p, a, h, ok := comboBlank(canon); ok {
+			n.Prompt, n.Answer, n.Hint = p, a, h
+		}
+	default:
+		n.Prompt = strings.ReplaceAll(n.Prompt, c.Command, canon)
+	}
+	return n, true
+}
+
+// rehash moves every command-derived card to its current ID. Cards that now
+// share an ID are merged by dedupeCards, keeping the most recent progress.
+func rehash(cards []Card, now time.Time) (out []Card, moved map[string]string, skipped int) {
+	moved = map[string]string{}
+	out = make([]Card, 0, len(cards))
+	for _, c := range cards {
+		n, ok := rehashCard(c, now)
+		if !ok {
+			skipped++
+		} else if n.ID != c.ID {
+			moved[c.ID] = n.ID
+		}
+		out = append(out, n)
+	}
+	return dedupeCards(out), moved, skipped
+}
+
+// renameReviews points logged reviews of moved cards at their new IDs.
+func renameReviews(moved map[string]string) (int, error) {
+	reviews, err := LoadReviews()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i, e := range reviews {
+		if id, ok := moved[e.CardID]; ok {
+			reviews[i].CardID = id
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return n, store.SaveReviews(reviews)
+}
+
+func runRehash(args []string) error {
+	fs := flag.NewFlagSet("rehash", flag.ExitOnError)
+	dry := fs.Bool("dry-run", false, "only list the cards that would move")
+	_ = fs.Parse(args)
+	now := time.Now()
+	var moved map[string]string
+	merged := 0
+	err := updateCards(func(cards []Card) []Card {
+		out, mv, skipped := rehash(cards, now)
+		moved = mv
+		byID := map[string]Card{}
+		for _, c := range cards {
+			byID[c.ID] = c
+		}
+		for old, id := range moved {
+			fmt.Printf("%s → %s  %s\n", shortID(old), shortID(id), byID[old].Command)
+		}
+		merged = len(cards) - len(out)
+		fmt.Printf("%d cards get new IDs (%d merged into another card), %d unchanged, %d skipped (IDs not derived from a command)\n",
+			len(moved), merged, len(cards)-len(moved)-skipped, skipped)
+		if *dry || len(moved) == 0 {
+			return nil
+		}
+		return out
+	})
+	if err != nil || *dry || len(moved) == 0 {
+		return err
+	}
+	n, err := renameReviews(moved)
+	if err != nil {
+		return fmt.Errorf("cards saved, but the review log was not updated: %w", err)
+	}
+	logger.Info("rehash", "moved", len(moved), "merged", merged, "reviews", n)
+	fmt.Printf("Saved; %d logged reviews now point at the new IDs.\n", n)
+	return nil
+}
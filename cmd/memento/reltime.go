@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"memento/pkg/srs"
+)
+
+// absoluteTimes is display.absolute_times: print local timestamps instead of "in 3d".
+var absoluteTimes bool
+
+func dayStart(t time.Time) time.Time { return srs.DayStart(t) }
+
+// humanTime renders t relative to now: "now", "in 40m", "5h ago", "in 3d".
+// Whole days are counted in review days (TZ, day_start_hour), so something due
+// at 09:00 tomorrow is "in 1d" even when it is only 10 hours away at 23:00.
+func humanTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	if absoluteTimes {
+		return t.Local().Format("2006-01-02 15:04")
+	}
+	d := t.Sub(now)
+	past := d < 0
+	if past {
+		d = -d
+	}
+	var s string
+	switch days := calendarDays(now, t); {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", int(d.Minutes()))
+	case days == 0:
+		s = fmt.Sprintf("%dh", int(d.Hours()))
+	case days < 60:
+		s = fmt.Sprintf("%dd", days)
+	case days < 730:
+		s = fmt.Sprintf("%dmo", days/30)
+	default:
+		s = fmt.Sprintf("%dy", days/365)
+	}
+	if past {
+		return s + " ago"
+	}
+	return "in " + s
+}
+
+// calendarDays is the number of review-day boundaries between a and b (unsigned).
+func calendarDays(a, b time.Time) int {
+	day := func(t time.Time) time.Time {
+		y, m, d := dayStart(t).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	n := int(day(b).Sub(day(a)).Hours() / 24)
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func dueLabel(c Card, now time.Time) string {
+	if c.Suspended {
+		return "suspended"
+	}
+	if c.NextDue.IsZero() {
+		return "due now"
+	}
+	return "due " + humanTime(c.NextDue, now)
+}
+
+func reviewedLabel(c Card, now time.Time) string {
+	if c.LastReviewed.IsZero() {
+		return "never reviewed"
+	}
+	return "reviewed " + humanTime(c.LastReviewed, now)
+}
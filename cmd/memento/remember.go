@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"memento/pkg/ingest"
+)
+
+// Shell widgets bound to Ctrl+X m (Ctrl+M itself is Enter in most terminals).
+// They card the line being edited, or the previous command if the line is empty.
+var rememberWidgets = map[string]string{
+	"zsh": `_memento_remember() {
+  local cmd=${BUFFER:-$(fc -ln -1)}
+  zle -I
+  memento remember --shell zsh -- "$cmd"
+  zle reset-prompt
+}
+zle -N _memento_remember
+bindkey '^Xm' _memento_remember
+`,
+	"bash": `_memento_remember() {
+  local cmd=${READLINE_LINE:-$(HISTTIMEFORMAT= history 1 | sed 's/^ *[0-9]* *//')}
+  memento remember --shell bash -- "$cmd"
+}
+bind -x '"\C-xm": _memento_remember'
+`,
+	"fish": `function _memento_remember
+  set -l cmd (commandline)
+  test -z "$cmd"; and set cmd $history[1]
+  memento remember --shell fish -- "$cmd"
+  commandline -f repaint
+end
+bind \cxm _memento_remember
+`,
+}
+
+// lastHistoryCommand returns the newest non-memento command of the most
+// recently modified known history file.
+func lastHistoryCommand() (string, error) {
+	paths := ingest.GuessHistoryFiles()
+	var newest string
+	var newestMod time.Time
+	for _, p := range paths {
+		if st, err := os.Stat(p); err == nil && st.ModTime().After(newestMod) {
+			newest, newestMod = p, st.ModTime()
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("no history file found")
+	}
+	last := ""
+	ingest.Walk([]ingest.Source{{Path: newest, Shell: ingest.SniffShell(newest)}}, func(cmd string, _ time.Time, _ Origin) {
+		if cmd != "" && !strings.HasPrefix(cmd, "memento ") {
+			last = cmd
+		}
+	})
+	if last == "" {
+		return "", fmt.Errorf("%s: no commands", newest)
+	}
+	return last, nil
+}
+
+func runRemember(args []string) error {
+	fs := flag.NewFlagSet("remember", flag.ExitOnError)
+	last := fs.Bool("last", false, "card the most recent command from history")
+	clip := fs.Bool("clipboard", false, "card the command on the system clipboard")
+	shell := fs.String("shell", "", "shell the command came from (set by widgets)")
+	widget := fs.String("print-widget", "", "print the key-binding widget for zsh|bash|fish")
+	_ = fs.Parse(args)
+
+	if *widget != "" {
+		w, ok := rememberWidgets[*widget]
+		if !ok {
+			return fmt.Errorf("no widget for shell %q", *widget)
+		}
+		fmt.Print(w)
+		return nil
+	}
+
+	raw := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	from := "remember"
+	if *clip {
+		if raw != "" || *last {
+			return fmt.Errorf("--clipboard takes no command and can't be combined with --last")
+		}
+		text, err := readClipboard()
+		if err != nil {
+			return err
+		}
+		if raw, err = clipboardCommand(text); err != nil {
+			return err
+		}
+		from = "clipboard"
+	}
+	if *last || raw == "" {
+		var err error
+		if raw, err = lastHistoryCommand(); err != nil {
+			return err
+		}
+	}
+	if isSensitive(raw) {
+		return fmt.Errorf("refusing to card a sensitive command (see ingest.sensitive_commands)")
+	}
+	raw = scrub(raw)
+	canon := normalizeCommand(raw)
+	if canon == "" {
+		return fmt.Errorf("nothing to remember")
+	}
+
+	now := time.Now()
+	host, _ := os.Hostname()
+	origin := Origin{Host: host, Shell: *shell, File: from, FirstSeen: now, LastSeen: now}
+	return updateCards(func(cards []Card) []Card {
+		if i, err := findCard(cards, hash(canon)); err == nil {
+			cards[i].NextDue = now
+			cards[i].Origins = mergeOrigin(cards[i].Origins, origin)
+			cards[i].Example = exampleOf(canon, raw)
+			fmt.Println("Already a card; due now:", cards[i].Prompt)
+			return cards
+		}
+		c := newCard(canon, origin, now)
+		c.Example = exampleOf(canon, raw)
+		fmt.Println("Remembered:", c.Prompt)
+		return append(cards, c)
+	})
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"memento/pkg/srs"
+)
+
+// reviewsToday counts logged reviews since the start of the current review day.
+func reviewsToday(now time.Time) (int, error) {
+	reviews, err := LoadReviews()
+	if err != nil {
+		return 0, err
+	}
+	start, n := dayStart(now), 0
+	for _, r := range reviews {
+		if !r.At.Before(start) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// gradeAndLog grades the card and records the review. A correct answer that
+// needed a hint or earned only partial credit (score < 1) is graded hard.
+func gradeAndLog(c *Card, answer string, correct, hinted bool, score float64, took time.Duration, now time.Time) (ReviewEntry, error) {
+	before := c.Box
+	srs.Review(c, answer, correct, hinted || score < 1, took, now)
+	logger.Info("graded", "id", shortID(c.ID), "scheduler", srs.SchedulerFor(*c), "correct", correct, "hinted", hinted,
+		"box_before", before, "box_after", c.Box, "next_due", c.NextDue)
+	e := ReviewEntry{CardID: c.ID, At: now, Correct: correct, Hinted: hinted, BoxBefore: before, BoxAfter: c.Box, Answer: answer, TookMS: took.Milliseconds()}
+	if score < 1 {
+		e.Score = score
+	}
+	return e, AppendReview(e)
+}
+
+// ReviewSession summarises one `memento review` run (post-review hook payload).
+type ReviewSession struct {
+	Started   time.Time     `json:"started"`
+	Ended     time.Time     `json:"ended"`
+	Queued    int           `json:"queued"`
+	Reviewed  int           `json:"reviewed"`
+	Correct   int           `json:"correct"`
+	Snoozed   int           `json:"snoozed"`
+	Skipped   int           `json:"skipped"`
+	Relearned int           `json:"relearned"` // extra in-session answers to failed cards
+	Quit      bool          `json:"quit"`      // left before finishing the queue
+	Capped    bool          `json:"capped"`    // stopped at review.max_per_day
+	AvgMS     int64         `json:"avg_ms"`
+	P50MS     int64         `json:"p50_ms"`
+	P90MS     int64         `json:"p90_ms"`
+	Reviews   []ReviewEntry `json:"reviews"`
+}
+
+// timings fills the answer-time stats from the timed reviews.
+func (s *ReviewSession) timings() {
+	ms := []int64{}
+	var sum int64
+	for _, e := range s.Reviews {
+		if e.TookMS > 0 {
+			ms = append(ms, e.TookMS)
+			sum += e.TookMS
+		}
+	}
+	if len(ms) == 0 {
+		return
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i] < ms[j] })
+	s.AvgMS = sum / int64(len(ms))
+	s.P50MS = percentile(ms, 0.5)
+	s.P90MS = percentile(ms, 0.9)
+}
+
+// percentile uses the nearest-rank method on sorted values.
+func percentile(sorted []int64, p float64) int64 {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+func (s ReviewSession) String() string {
+	out := fmt.Sprintf("Reviewed %d of %d (%d correct)", s.Reviewed, s.Queued, s.Correct)
+	if s.Snoozed > 0 {
+		out += fmt.Sprintf(", %d snoozed", s.Snoozed)
+	}
+	if s.Skipped > 0 {
+		out += fmt.Sprintf(", %d skipped", s.Skipped)
+	}
+	if s.Relearned > 0 {
+		out += fmt.Sprintf(", %d relearning answers", s.Relearned)
+	}
+	if s.Capped {
+		out += " · daily cap reached, the rest rolls over to tomorrow"
+	}
+	if s.AvgMS > 0 {
+		sec := func(ms int64) string { return fmt.Sprintf("%.1fs", float64(ms)/1000) }
+		out += fmt.Sprintf(" · answer time avg %s, p50 %s, p90 %s", sec(s.AvgMS), sec(s.P50MS), sec(s.P90MS))
+	}
+	return out
+}
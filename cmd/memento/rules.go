@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// User rules (ingest.rules in config) are expr-lang expressions evaluated per
+// normalized command:
+//
+//	"tricky": "pipes >= 2 or has_flag(\"--force\") or builtin_tricky"
+//	"cloze":  "tool == \"terraform\" ? tokens[1] : builtin_answer"
+//
+// tricky must yield a bool and replaces the built-in heuristic; cloze yields
+// the token to blank ("" keeps the built-in choice).
+var trickyRule, clozeRule *vm.Program
+
+// ruleWarned keeps a broken rule from printing once per command.
+var ruleWarned = map[string]bool{}
+
+func ruleEnv(cmd string) map[string]any {
+	toks := strings.Fields(cmd)
+	flags := []string{}
+	for _, t := range toks {
+		if strings.HasPrefix(t, "-") {
+			flags = append(flags, t)
+		}
+	}
+	tool := ""
+	if len(toks) > 0 {
+		tool = toks[0]
+	}
+	_, builtinAnswer, _ := heuristicCloze(cmd)
+	return map[string]any{
+		"cmd":    cmd,
+		"tool":   tool,
+		"tokens": toks,
+		"flags":  flags,
+		"pipes":  strings.Count(cmd, "|") - 2*strings.Count(cmd, "||"),
+		"length": len(cmd),
+		"has_flag": func(f string) bool {
+			for _, x := range flags {
+				if x == f || strings.HasPrefix(x, f+"=") {
+					return true
+				}
+			}
+			return false
+		},
+		"has_token":      func(t string) bool { return contains(toks, t) },
+		"builtin_tricky": builtinTricky(cmd),
+		"builtin_answer": builtinAnswer,
+	}
+}
+
+func compileRules(r RulesConfig) error {
+	trickyRule, clozeRule = nil, nil
+	env := ruleEnv("")
+	if r.Tricky != "" {
+		p, err := expr.Compile(r.Tricky, expr.Env(env), expr.AsBool())
+		if err != nil {
+			return fmt.Errorf("ingest.rules.tricky: %w", err)
+		}
+		trickyRule = p
+	}
+	if r.Cloze != "" {
+		p, err := expr.Compile(r.Cloze, expr.Env(env), expr.AsKind(reflect.String))
+		if err != nil {
+			return fmt.Errorf("ingest.rules.cloze: %w", err)
+		}
+		clozeRule = p
+	}
+	return nil
+}
+
+// runRule evaluates p for cmd; ok is false (after one warning) if it fails.
+func runRule(name string, p *vm.Program, cmd string) (any, bool) {
+	v, err := expr.Run(p, ruleEnv(cmd))
+	if err != nil {
+		if !ruleWarned[name] {
+			fmt.Fprintf(os.Stderr, "warning: ingest.rules.%s: %v (falling back to built-in)\n", name, err)
+			ruleWarned[name] = true
+		}
+		return nil, false
+	}
+	return v, true
+}
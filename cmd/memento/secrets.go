@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Provider-specific secret rules in gitleaks' format. A handful are built in;
+// more can be loaded from a gitleaks.toml (scrub.gitleaks_config, or
+// gitleaks.toml next to config.json).
+
+type secretRule struct {
+	ID          string
+	re          *regexp.Regexp
+	secretGroup int
+	keywords    []string
+}
+
+// gitleaksFile mirrors the parts of gitleaks.toml we understand.
+type gitleaksFile struct {
+	Rules []struct {
+		ID          string   `toml:"id"`
+		Description string   `toml:"description"`
+		Regex       string   `toml:"regex"`
+		SecretGroup int      `toml:"secretGroup"`
+		Keywords    []string `toml:"keywords"`
+	} `toml:"rules"`
+}
+
+var builtinSecretRules = mustSecretRules([][3]string{
+	{"github-pat", `\b(?:ghp|gho|ghu|ghs|ghr)_[0-9A-Za-z]{36}\b`, "gh"},
+	{"github-fine-grained-pat", `\bgithub_pat_[0-9A-Za-z_]{82}\b`, "github_pat_"},
+	{"gitlab-pat", `\bglpat-[0-9A-Za-z\-_]{20}\b`, "glpat-"},
+	{"slack-token", `\bxox[baprs]-[0-9A-Za-z-]{10,}\b`, "xox"},
+	{"slack-webhook", `https://hooks\.slack\.com/services/[A-Za-z0-9+/]{40,}`, "hooks.slack.com"},
+	{"aws-access-key-id", `\b(?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`, ""},
+	{"stripe-key", `\b(?:sk|rk)_(?:test|live)_[0-9A-Za-z]{24,}\b`, "_test_,_live_"},
+	{"openai-key", `\bsk-[A-Za-z0-9_-]{20,}\b`, "sk-"},
+	{"google-api-key", `\bAIza[0-9A-Za-z\-_]{35}\b`, "aiza"},
+	{"jwt", `\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`, "eyj"},
+	{"private-key", `-----BEGIN[ A-Z]*PRIVATE KEY-----`, "private key"},
+})
+
+// secretRules is extended from config in configure().
+var secretRules = builtinSecretRules
+
+func mustSecretRules(defs [][3]string) []secretRule {
+	out := make([]secretRule, 0, len(defs))
+	for _, d := range defs {
+		r := secretRule{ID: d[0], re: regexp.MustCompile(d[1])}
+		if d[2] != "" {
+			r.keywords = strings.Split(d[2], ",")
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func LoadGitleaksRules(path string) ([]secretRule, error) {
+	var f gitleaksFile
+	if _, err := toml.DecodeFile(path, &f); err != nil {
+		return nil, err
+	}
+	out := []secretRule{}
+	for _, r := range f.Rules {
+		if r.Regex == "" {
+			continue // path-only rules don't apply to commands
+		}
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule %s: %w", path, r.ID, err)
+		}
+		if r.SecretGroup > re.NumSubexp() {
+			return nil, fmt.Errorf("%s: rule %s: secretGroup %d out of range", path, r.ID, r.SecretGroup)
+		}
+		kw := make([]string, len(r.Keywords))
+		for i, k := range r.Keywords {
+			kw[i] = strings.ToLower(k)
+		}
+		out = append(out, secretRule{ID: r.ID, re: re, secretGroup: r.SecretGroup, keywords: kw})
+	}
+	return out, nil
+}
+
+// gitleaksPath resolves the configured rule file, or gitleaks.toml in the config dir.
+func gitleaksPath(cfg Config) string {
+	if cfg.Scrub.GitleaksConfig != "" {
+		return cfg.Scrub.GitleaksConfig
+	}
+	d, err := configDir()
+	if err != nil {
+		return ""
+	}
+	p := filepath.Join(d, "gitleaks.toml")
+	if _, err := os.Stat(p); errors.Is(err, os.ErrNotExist) {
+		return ""
+	}
+	return p
+}
+
+// apply masks every match (or just its secretGroup) with <SECRET>.
+func (r secretRule) apply(s string) (string, bool) {
+	if len(r.keywords) > 0 {
+		low := strings.ToLower(s)
+		hit := false
+		for _, k := range r.keywords {
+			if strings.Contains(low, k) {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return s, false
+		}
+	}
+	locs := r.re.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) == 0 {
+		return s, false
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range locs {
+		lo, hi := loc[0], loc[1]
+		if g := r.secretGroup; g > 0 && loc[2*g] >= 0 {
+			lo, hi = loc[2*g], loc[2*g+1]
+		}
+		b.WriteString(s[last:lo])
+		b.WriteString("<SECRET>")
+		last = hi
+	}
+	b.WriteString(s[last:])
+	return b.String(), true
+}
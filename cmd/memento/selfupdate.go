@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"memento/pkg/storage"
+)
+
+// releaseKey is the ed25519 public key that signs checksums.txt, set by
+// release builds (-ldflags "-X main.releaseKey=<base64>"). Without it
+// (source builds) self-update refuses to run unless --insecure, and then
+// checks the checksum only.
+var releaseKey = ""
+
+const releaseAPI = "https://api.github.com/repos/kamaterasu/Memonto/releases/latest"
+
+// release is the part of the GitHub release API response self-update reads.
+// Each release carries one raw binary per platform (memento_<os>_<arch>),
+// checksums.txt in sha256sum format, and checksums.txt.sig.
+type release struct {
+	Tag    string `json:"tag_name"`
+	Assets []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func (r release) asset(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+func assetName() string {
+	n := "memento_" + runtime.GOOS + "_" + runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		n += ".exe"
+	}
+	return n
+}
+
+// semver splits "v1.2.3[-pre]" into numbers; ok is false for anything else ("dev").
+func semver(v string) (n [3]int, pre string, ok bool) {
+	v, pre, _ = strings.Cut(strings.TrimPrefix(v, "v"), "-")
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return n, "", false
+	}
+	for i, p := range parts {
+		x, err := strconv.Atoi(p)
+		if err != nil {
+			return n, "", false
+		}
+		n[i] = x
+	}
+	return n, pre, true
+}
+
+// newerVersion reports whether b is a later release than a.
+func newerVersion(a, b string) bool {
+	na, pa, oka := semver(a)
+	nb, pb, okb := semver(b)
+	if !oka || !okb {
+		return false
+	}
+	for i := range na {
+		if na[i] != nb[i] {
+			return nb[i] > na[i]
+		}
+	}
+	return pa != "" && (pb == "" || pb > pa) // 1.2.0-rc1 < 1.2.0
+}
+
+// sumFor finds name's checksum in a sha256sum listing.
+func sumFor(list []byte, name string) (string, error) {
+	for _, line := range strings.Split(string(list), "\n") {
+		f := strings.Fields(line)
+		if len(f) == 2 && strings.TrimPrefix(f[1], "*") == name {
+			return parseSum([]byte(f[0]))
+		}
+	}
+	return "", fmt.Errorf("checksums.txt has no entry for %s", name)
+}
+
+// verifyRelease checks sums against its signature with releaseKey.
+func verifyRelease(sums, sig []byte) error {
+	key, err := base64.StdEncoding.DecodeString(releaseKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("bad release key built into this binary")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		raw = sig // binary signature
+	}
+	if !ed25519.Verify(key, sums, raw) {
+		return errors.New("checksums.txt: bad signature")
+	}
+	return nil
+}
+
+func runSelfUpdate(args []string) error {
+	fs := flag.NewFlagSet("self-update", flag.ExitOnError)
+	check := fs.Bool("check", false, "only report whether a newer release exists")
+	force := fs.Bool("force", false, "install the latest release even if this build is current (or a dev build)")
+	insecure := fs.Bool("insecure", false, "on a build without a release key, install with the checksum check only")
+	_ = fs.Parse(args)
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	if strings.Contains(exe, "/Cellar/") || strings.Contains(exe, "/nix/store/") {
+		return fmt.Errorf("%s is managed by a package manager; update it there (e.g. brew upgrade memento)", exe)
+	}
+
+	b, err := fetchURL(releaseAPI)
+	if err != nil {
+		return err
+	}
+	var rel release
+	if err := json.Unmarshal(b, &rel); err != nil {
+		return fmt.Errorf("release info: %w", err)
+	}
+	if rel.Tag == "" {
+		return errors.New("release info: no tag")
+	}
+	cur := buildInfo().Version
+	switch {
+	case *force:
+	case cur == "dev" || strings.HasPrefix(cur, "v0.0.0-"): // source build (pseudo-version)
+		fmt.Printf("This is a development build; the latest release is %s (--force to install it).\n", rel.Tag)
+		return nil
+	case !newerVersion(cur, rel.Tag):
+		fmt.Printf("memento %s is up to date.\n", cur)
+		return nil
+	}
+	if *check {
+		fmt.Printf("memento %s is available (you have %s); run memento self-update.\n", rel.Tag, cur)
+		return nil
+	}
+
+	if releaseKey == "" {
+		if !*insecure {
+			return errors.New("this build has no release key to verify the download's signature; install a release build, or pass --insecure to rely on the checksum alone")
+		}
+		fmt.Fprintln(os.Stderr, "warning: --insecure: the signature is not checked; the checksum only catches corrupt downloads, not a tampered release")
+	}
+	name := assetName()
+	binURL, ok := rel.asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no build for %s/%s", rel.Tag, runtime.GOOS, runtime.GOARCH)
+	}
+	sumsURL, ok := rel.asset("checksums.txt")
+	if !ok {
+		return fmt.Errorf("release %s publishes no checksums.txt; not installing an unverified binary", rel.Tag)
+	}
+	sums, err := fetchURL(sumsURL)
+	if err != nil {
+		return fmt.Errorf("checksums: %w", err)
+	}
+	if releaseKey != "" {
+		sigURL, ok := rel.asset("checksums.txt.sig")
+		if !ok {
+			return fmt.Errorf("release %s is not signed", rel.Tag)
+		}
+		sig, err := fetchURL(sigURL)
+		if err != nil {
+			return fmt.Errorf("signature: %w", err)
+		}
+		if err := verifyRelease(sums, sig); err != nil {
+			return err
+		}
+	}
+	want, err := sumFor(sums, name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Downloading memento %s (%s)…\n", rel.Tag, name)
+	bin, err := fetchURLMax(binURL, 256<<20, 5*time.Minute)
+	if err != nil {
+		return err
+	}
+	if got := storage.Checksum(bin); got != want {
+		return fmt.Errorf("checksum mismatch: got %s, published %s", got[:12], want[:12])
+	}
+	if err := storage.WriteFileAtomic(exe, bin, 0o755); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("can't replace %s (%w); rerun with the permissions it was installed with", storage.TildePath(exe), err)
+		}
+		return err
+	}
+	logger.Info("self-update", "from", cur, "to", rel.Tag, "path", exe, "signed", releaseKey != "")
+	fmt.Printf("Updated %s: %s → %s.\n", storage.TildePath(exe), cur, rel.Tag)
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Commands that invoke secret-adjacent tools are dropped entirely during
+// ingest and capture — scrubbing regexes can't be trusted with these.
+// Entries match the command name, or a command-name prefix ("aws sts").
+var defaultSensitiveCommands = []string{
+	"gpg", "gpg2", "pass", "gopass", "vault", "op", "bw", "lpass", "keepassxc-cli", "security",
+	"age", "sops", "ssh-keygen", "ssh-add", "htpasswd", "openssl passwd",
+	"aws sts", "aws secretsmanager", "aws ssm get-parameter", "aws ssm get-parameters",
+	"gcloud auth", "az login", "kubectl create secret", "docker login", "npm login", "gh auth",
+}
+
+// sensitiveCommands is replaced by config (ingest.sensitive_commands) in configure().
+var sensitiveCommands = defaultSensitiveCommands
+
+var (
+	cmdSeparators = regexp.MustCompile(`\|\||&&|[|;&]`)
+	envAssign     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+	cmdWrappers   = set("sudo", "doas", "env", "command", "exec", "time", "nohup")
+)
+
+// isSensitive reports whether any segment of a (possibly piped) command runs a sensitive tool.
+func isSensitive(s string) bool {
+	for _, seg := range cmdSeparators.Split(s, -1) {
+		toks := strings.Fields(seg)
+		for len(toks) > 0 && (cmdWrappers[toks[0]] || envAssign.MatchString(toks[0])) {
+			toks = toks[1:]
+		}
+		if len(toks) == 0 {
+			continue
+		}
+		toks[0] = toks[0][strings.LastIndex(toks[0], "/")+1:] // /usr/bin/gpg → gpg
+		for _, entry := range sensitiveCommands {
+			want := strings.Fields(entry)
+			if len(want) == 0 || len(want) > len(toks) {
+				continue
+			}
+			match := true
+			for i, w := range want {
+				if toks[i] != w {
+					match = false
+					break
+				}
+			}
+			if match {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+	"memento/pkg/ingest"
+)
+
+// Sequence cards: commands run in quick succession form workflows
+// (stash → rebase → pop). We look for recurring steps inside temporal
+// clusters and ask for the next one.
+
+const (
+	sequenceGap    = 3 * time.Minute // max pause between steps of one workflow
+	sequenceWindow = 2               // preceding steps shown as context
+	sequenceMinRun = 2               // a workflow must recur this often
+)
+
+// ParseTimeline returns every timestamped command in chronological order (not deduped).
+func ParseTimeline(srcs []ingest.Source, w ingest.Window) []CommandEvent {
+	out := []CommandEvent{}
+	ingest.Walk(srcs, func(raw string, when time.Time, origin Origin) {
+		if when.IsZero() || !w.Contains(when) {
+			return
+		}
+		raw = scrub(raw)
+		if isIgnorable(raw) {
+			return
+		}
+		out = append(out, CommandEvent{When: when, First: when, Command: normalizeCommand(raw), Origin: origin})
+	})
+	sort.SliceStable(out, func(i, j int) bool { return out[i].When.Before(out[j].When) })
+	return out
+}
+
+// clusters splits a timeline wherever the gap between commands exceeds sequenceGap.
+func clusters(timeline []CommandEvent) [][]string {
+	var out [][]string
+	var cur []string
+	for i, ev := range timeline {
+		if i > 0 && ev.When.Sub(timeline[i-1].When) > sequenceGap {
+			if len(cur) > 1 {
+				out = append(out, cur)
+			}
+			cur = nil
+		}
+		if len(cur) > 0 && cur[len(cur)-1] == ev.Command {
+			continue // immediate repeats aren't a workflow step
+		}
+		cur = append(cur, ev.Command)
+	}
+	if len(cur) > 1 {
+		out = append(out, cur)
+	}
+	return out
+}
+
+func GenerateSequenceCards(timeline []CommandEvent, existing []Card, now time.Time) []Card {
+	type step struct{ ctx, next string }
+	counts := map[step]int{}
+	order := []step{}
+	for _, cl := range clusters(timeline) {
+		for i := 1; i < len(cl); i++ {
+			lo := i - sequenceWindow
+			if lo < 0 {
+				lo = 0
+			}
+			st := step{strings.Join(cl[lo:i], " → "), cl[i]}
+			if counts[st] == 0 {
+				order = append(order, st)
+			}
+			counts[st]++
+		}
+	}
+
+	have := map[string]bool{}
+	for _, c := range existing {
+		have[c.ID] = true
+	}
+	out := []Card{}
+	for _, st := range order {
+		if counts[st] < sequenceMinRun {
+			continue
+		}
+		id := hash("seq:" + st.ctx + "\n" + st.next)
+		if have[id] {
+			continue
+		}
+		have[id] = true
+		out = append(out, Card{
+			ID: id, Type: cards.Sequence, Prompt: st.ctx + " → _____", Answer: st.next,
+			Hint:    fmt.Sprintf("Next step of a workflow you've run %d times", counts[st]),
+			Command: st.ctx + " → " + st.next,
+			Tags:    unique(append(deriveTags(st.next), sequenceTag)), Box: 1, NextDue: now, SeenCount: counts[st],
+		})
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"memento/pkg/storage"
+)
+
+// savedSession is an unfinished review: what's left of the queue, which of
+// those cards are in-session relearning, and the stats so far. It is
+// rewritten after every answer so a dead terminal loses nothing.
+type savedSession struct {
+	Saved   time.Time     `json:"saved"`
+	Queue   []string      `json:"queue"`
+	Relearn []string      `json:"relearn,omitempty"`
+	Session ReviewSession `json:"session"`
+	PickUp  *reviewFilter `json:"pick_up,omitempty"` // set when review.pick_up_new was on
+}
+
+// reviewFilter is the set of cards `memento review` was asked for.
+type reviewFilter struct {
+	Query       string `json:"query,omitempty"`
+	Host        string `json:"host,omitempty"`
+	SkipMissing bool   `json:"skip_missing_tools,omitempty"`
+	Mode        string `json:"mode"`
+}
+
+// admit reports whether a card belongs to f; q is f.Query parsed.
+func (f reviewFilter) admit(q Query, cfg Config) func(Card) bool {
+	return func(c Card) bool {
+		return q.Match(c, time.Now()) &&
+			(f.Host == "" || c.FromHost(f.Host)) &&
+			(!f.SkipMissing && !cfg.Review.SkipMissingTools || !ToolMissing(c)) &&
+			(f.Mode == "all" || c.Kind() == f.Mode)
+	}
+}
+
+func sessionPath() (string, error) {
+	d, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "session.json"), nil
+}
+
+func loadSession() (*savedSession, error) {
+	p, err := sessionPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s savedSession
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	if len(s.Queue) == 0 {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func clearSession() error {
+	p, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// remaining is the part of the queue not yet answered.
+func (m model) remaining() []Card {
+	if m.checking {
+		return m.cards[m.idx+1:]
+	}
+	return m.cards[m.idx:]
+}
+
+// saveSession records the unfinished session; a finished one is removed.
+func (m model) saveSession() {
+	if !m.persist {
+		return
+	}
+	rest := m.remaining()
+	if len(rest) == 0 {
+		_ = clearSession()
+		return
+	}
+	s := savedSession{Saved: time.Now(), Session: m.session, PickUp: m.pickUpBy}
+	for _, c := range rest {
+		s.Queue = append(s.Queue, c.ID)
+	}
+	for id := range m.relearn {
+		s.Relearn = append(s.Relearn, id)
+	}
+	p, err := sessionPath()
+	if err != nil {
+		return
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	_ = storage.WriteFileAtomic(p, b, 0o644)
+}
+
+// resumeModel rebuilds a saved session from the current deck; cards deleted
+// or suspended since are dropped.
+func resumeModel(s savedSession, all []Card, cfg Config) model {
+	byID := map[string]Card{}
+	for _, c := range all {
+		byID[c.ID] = c
+	}
+	queue := []Card{}
+	for _, id := range s.Queue {
+		if c, ok := byID[id]; ok && !c.Suspended {
+			queue = append(queue, c)
+		}
+	}
+	m := initialModel(queue, all, cfg)
+	m.persist = true
+	m.session = s.Session
+	for _, id := range s.Relearn {
+		m.relearn[id] = true
+	}
+	if s.PickUp != nil {
+		if q, err := ParseQuery(s.PickUp.Query); err == nil {
+			m.pickUp, m.pickUpBy = s.PickUp.admit(q, cfg), s.PickUp
+		}
+	}
+	return m
+}
+
+// offerResume asks whether to pick up s; --resume / --fresh answer up front.
+func offerResume(s savedSession, resume, fresh bool) bool {
+	switch {
+	case resume:
+		return true
+	case fresh || !interactive():
+		return false
+	}
+	a := asker{r: bufio.NewReader(os.Stdin), w: os.Stdout}
+	return a.yes(fmt.Sprintf("Resume the session you left %s (%d cards left)?", humanTime(s.Saved, time.Now()), len(s.Queue)), true)
+}
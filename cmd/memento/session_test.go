@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestSessionRoundTrip(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	all := []Card{
+		{ID: "a", Command: "git status", Tags: []string{"git"}, Box: 1},
+		{ID: "b", Command: "git log", Tags: []string{"git"}, Box: 1},
+		{ID: "c", Command: "docker ps", Tags: []string{"docker"}, Box: 1},
+	}
+	filter := reviewFilter{Query: "tag:git", Mode: "all"}
+	q, err := ParseQuery(filter.Query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := initialModel(all[:2], all, Config{})
+	m.persist = true
+	m.relearn["a"] = true
+	m.pickUp, m.pickUpBy = filter.admit(q, Config{}), &filter
+	m.saveSession()
+
+	s, err := loadSession()
+	if err != nil || s == nil {
+		t.Fatalf("loadSession = %v, %v", s, err)
+	}
+	r := resumeModel(*s, all, Config{})
+	if got := idsOf(r.remaining()); got != idsOf(all[:2]) {
+		t.Errorf("queue %q, want %q", got, idsOf(all[:2]))
+	}
+	if !r.persist || !r.relearn["a"] {
+		t.Errorf("persist %v, relearn %v", r.persist, r.relearn)
+	}
+	if r.pickUp == nil || r.pickUpBy == nil || *r.pickUpBy != filter {
+		t.Fatalf("pick-up not restored: %+v", r.pickUpBy)
+	}
+	if !r.pickUp(all[1]) || r.pickUp(all[2]) {
+		t.Errorf("restored pick-up admits git %v, docker %v; want true, false", r.pickUp(all[1]), r.pickUp(all[2]))
+	}
+
+	m.pickUp, m.pickUpBy = nil, nil
+	m.saveSession()
+	if s, err := loadSession(); err != nil || s.PickUp != nil || resumeModel(*s, all, Config{}).pickUp != nil {
+		t.Errorf("pick-up off came back on: %+v, %v", s, err)
+	}
+}
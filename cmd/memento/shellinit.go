@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shell integration printed by `memento init <shell>`: capture hook, remember
+// widget, due-count prompt segment and completions.
+
+var captureHooks = map[string]string{
+	"zsh": `_memento_capture() { memento capture --shell zsh --cwd "$PWD" -- "$1" &! }
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec _memento_capture
+`,
+	"bash": `_memento_capture() {
+  [ -n "$COMP_LINE" ] && return
+  local cmd=$(HISTTIMEFORMAT= history 1 | sed 's/^ *[0-9]* *//')
+  [ "$cmd" = "$_memento_last" ] && return
+  _memento_last=$cmd
+  (memento capture --shell bash --cwd "$PWD" -- "$cmd" &)
+}
+PROMPT_COMMAND="_memento_capture${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+`,
+	"fish": `function _memento_capture --on-event fish_preexec
+  memento capture --shell fish --cwd "$PWD" -- $argv[1] &
+  disown 2>/dev/null
+end
+`,
+}
+
+// Prompt segments expose the due count; add them to PROMPT/PS1/fish_right_prompt yourself.
+var promptSegments = map[string]string{
+	"zsh": `memento_prompt() { local n=$(memento status 2>/dev/null); [ "${n:-0}" -gt 0 ] && print -n "🧠$n" }
+# e.g. RPROMPT='$(memento_prompt)'  (needs: setopt prompt_subst)
+`,
+	"bash": `memento_prompt() { local n=$(memento status 2>/dev/null); [ "${n:-0}" -gt 0 ] && printf '🧠%s' "$n"; }
+# e.g. PS1='$(memento_prompt) '"$PS1"
+`,
+	"fish": `function memento_prompt
+  set -l n (memento status 2>/dev/null)
+  test -n "$n"; and test $n -gt 0; and printf '🧠%s' $n
+end
+# e.g. call memento_prompt from fish_right_prompt
+`,
+}
+
+func completionScript(shell string) string {
+	cmds := strings.Join(subcommands(), " ")
+	switch shell {
+	case "zsh":
+		return fmt.Sprintf("_memento() { _arguments '1:command:(%s)' '*:file:_files' }\n(( $+functions[compdef] )) && compdef _memento memento\n", cmds)
+	case "bash":
+		return fmt.Sprintf("complete -o default -W %q memento\n", cmds)
+	case "fish":
+		return fmt.Sprintf("complete -c memento -f -n __fish_use_subcommand -a %q\n", cmds)
+	}
+	return ""
+}
+
+func ShellInit(shell string) (string, error) {
+	hook, ok := captureHooks[shell]
+	if !ok {
+		return "", fmt.Errorf("unsupported shell %q (zsh, bash, fish)", shell)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# memento %s integration — eval this from your shell rc\n", shell)
+	b.WriteString(hook)
+	b.WriteString(rememberWidgets[shell])
+	b.WriteString(promptSegments[shell])
+	b.WriteString(completionScript(shell))
+	return b.String(), nil
+}
+
+func runInit(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: memento init zsh|bash|fish")
+	}
+	s, err := ShellInit(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Print(s)
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// variantsOf lists sibling cards generated from the same command (other card kinds).
+func variantsOf(cards []Card, c Card) []Card {
+	out := []Card{}
+	for _, o := range cards {
+		if o.ID != c.ID && o.Command == c.Command {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+func runShow(args []string) error {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: memento show <id-prefix>")
+	}
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	i, err := findCard(cards, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	c := cards[i]
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+
+	if vs := variantsOf(cards, c); len(vs) > 0 {
+		fmt.Println("\nVariants:")
+		for _, v := range vs {
+			fmt.Printf("  %s  %-13s %s\n", shortID(v.ID), v.Kind(), firstLine(v.Prompt))
+		}
+	}
+
+	reviews, err := LoadReviews()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	fmt.Printf("\n%s, %s\n", dueLabel(c, now), reviewedLabel(c, now))
+	fmt.Println("\nReview history:")
+	n := 0
+	for _, r := range reviews {
+		if r.CardID != c.ID {
+			continue
+		}
+		mark := "✘"
+		if r.Correct {
+			mark = "✔"
+		}
+		fmt.Printf("  %-10s  %s  box %d → %d  %q\n", humanTime(r.At, now), mark, r.BoxBefore, r.BoxAfter, r.Answer)
+		n++
+	}
+	if n == 0 {
+		fmt.Println("  (never reviewed)")
+	}
+	return nil
+}
+
+func firstLine(s string) string {
+	for i, r := range s {
+		if r == '\n' {
+			return s[:i] + " …"
+		}
+	}
+	return s
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"memento/pkg/srs"
+)
+
+// simParams drive one Monte Carlo workload forecast.
+type simParams struct {
+	Days       int
+	Runs       int
+	Add        int     // new cards added today
+	NewPerDay  float64 // new cards added every day after that
+	NewCap     int     // new cards introduced per day (-1 = all)
+	MaxPerDay  int     // graded reviews per day (0 = no cap)
+	Accuracy   map[int]float64
+	DefaultAcc float64
+}
+
+// simDay is the average load on one simulated day.
+type simDay struct {
+	Reviews, New float64
+	Max          int
+}
+
+// historicalAccuracy is the share of correct answers per box before grading;
+// overall falls back to 85% until there are enough reviews to go on.
+func historicalAccuracy(reviews []ReviewEntry) (byBox map[int]float64, overall float64) {
+	right, total := map[int]int{}, map[int]int{}
+	r, n := 0, 0
+	for _, e := range reviews {
+		total[e.BoxBefore]++
+		n++
+		if e.Correct {
+			right[e.BoxBefore]++
+			r++
+		}
+	}
+	byBox = map[int]float64{}
+	for b, t := range total {
+		if t >= 10 { // too few answers say nothing about a box
+			byBox[b] = float64(right[b]) / float64(t)
+		}
+	}
+	if n < 20 {
+		return byBox, 0.85
+	}
+	return byBox, float64(r) / float64(n)
+}
+
+// newCardRate is how many cards a day were created over the last 30 days.
+func newCardRate(cards []Card, now time.Time) float64 {
+	n := 0
+	for _, c := range cards {
+		if now.Sub(c.Created) <= 30*24*time.Hour {
+			n++
+		}
+	}
+	return float64(n) / 30
+}
+
+// simulate replays p.Days review days p.Runs times with the real schedulers,
+// answering correctly at the historical rate for the card's box.
+func simulate(cards []Card, p simParams, now time.Time, rng *rand.Rand) []simDay {
+	out := make([]simDay, p.Days)
+	today := dayStart(now)
+	for run := 0; run < p.Runs; run++ {
+		deck := []Card{}
+		for _, c := range cards {
+			if !c.Suspended {
+				deck = append(deck, c)
+			}
+		}
+		added := 0.0
+		for d := 0; d < p.Days; d++ {
+			at := today.AddDate(0, 0, d).Add(12 * time.Hour)
+			if d == 0 {
+				at = now
+			}
+			fresh := p.NewPerDay * float64(d)
+			if d == 0 {
+				fresh = 0
+			}
+			for ; added < float64(p.Add)+fresh; added++ {
+				deck = append(deck, Card{ID: fmt.Sprintf("sim-%d", int(added)), Box: 1, NextDue: at})
+			}
+			due := []int{}
+			for i, c := range deck {
+				if !c.NextDue.After(at) {
+					due = append(due, i)
+				}
+			}
+			sort.SliceStable(due, func(a, b int) bool { return srs.Priority(deck[due[a]], at) > srs.Priority(deck[due[b]], at) })
+			reviews, news := 0, 0
+			for _, i := range due {
+				c := &deck[i]
+				if srs.IsNew(*c) {
+					if p.NewCap >= 0 && news >= p.NewCap {
+						continue
+					}
+					news++
+				}
+				if p.MaxPerDay > 0 && reviews >= p.MaxPerDay {
+					break
+				}
+				reviews++
+				acc, ok := p.Accuracy[c.Box]
+				if !ok {
+					acc = p.DefaultAcc
+				}
+				simGrade(c, rng.Float64() < acc, at)
+				if next := dayStart(at).AddDate(0, 0, 1); c.NextDue.Before(next) {
+					c.NextDue = next // relearned within the session
+				}
+			}
+			out[d].Reviews += float64(reviews) / float64(p.Runs)
+			out[d].New += float64(news) / float64(p.Runs)
+			out[d].Max = max(out[d].Max, reviews)
+		}
+	}
+	return out
+}
+
+func simGrade(c *Card, correct bool, now time.Time) {
+	if srs.SchedulerFor(*c) == "fsrs" {
+		r := srs.RateGood
+		if !correct {
+			r = srs.RateAgain
+		}
+		srs.FSRSGrade(c, r, now)
+	} else {
+		srs.Grade(c, correct, now)
+	}
+	srs.AlignDue(c, now)
+}
+
+func runSimulate(args []string) error {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	days := fs.Int("days", 90, "days to simulate")
+	runs := fs.Int("runs", 50, "Monte Carlo runs to average")
+	add := fs.Int("add", 0, "cards added today, e.g. from a big ingest or deck install")
+	newRate := fs.Float64("new", -1, "new cards per day (default: your rate over the last 30 days)")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "random seed")
+	_ = fs.Parse(args)
+	if *days < 1 || *runs < 1 {
+		return fmt.Errorf("--days and --runs must be at least 1")
+	}
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	reviews, err := LoadReviews()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	byBox, overall := historicalAccuracy(reviews)
+	if *newRate < 0 {
+		*newRate = newCardRate(cards, now)
+	}
+	p := simParams{Days: *days, Runs: *runs, Add: *add, NewPerDay: *newRate, NewCap: cfg.Review.NewPerSession,
+		MaxPerDay: cfg.Review.MaxPerDay, Accuracy: byBox, DefaultAcc: overall}
+	res := simulate(cards, p, now, rand.New(rand.NewSource(*seed)))
+
+	fmt.Printf("Simulating %d days × %d runs: %d cards", *days, *runs, len(cards))
+	if *add > 0 {
+		fmt.Printf(" + %d today", *add)
+	}
+	fmt.Printf(", %.1f new/day\n", *newRate)
+	if len(reviews) < 20 {
+		fmt.Printf("Too little review history; assuming %.0f%% correct.\n", 100*overall)
+	} else {
+		fmt.Printf("Accuracy from %d reviews: %.0f%% overall", len(reviews), 100*overall)
+		for b := 1; b <= srs.MaxBox(); b++ {
+			if a, ok := byBox[b]; ok {
+				fmt.Printf(", box %d %.0f%%", b, 100*a)
+			}
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+	total, peak, peakDay := 0.0, 0, 0
+	for w := 0; w < len(res); w += 7 {
+		end := min(w+7, len(res))
+		sum, news, top := 0.0, 0.0, 0
+		for _, d := range res[w:end] {
+			sum += d.Reviews
+			news += d.New
+			top = max(top, d.Max)
+		}
+		n := float64(end - w)
+		fmt.Printf("  week of %s  %5.1f reviews/day (%4.1f new)  worst day %d\n",
+			dayStart(now).AddDate(0, 0, w).Format("Mon 2006-01-02"), sum/n, news/n, top)
+	}
+	for i, d := range res {
+		total += d.Reviews
+		if d.Max > peak {
+			peak, peakDay = d.Max, i
+		}
+	}
+	avg := total / float64(len(res))
+	fmt.Printf("\nAverage %.1f reviews/day", avg)
+	if took := medianTook(reviews); took > 0 {
+		fmt.Printf(" (≈ %s/day at your median %s per card)", time.Duration(avg*float64(took)).Round(time.Minute), took.Round(100*time.Millisecond))
+	}
+	fmt.Printf("; worst day %d on %s.\n", peak, dayStart(now).AddDate(0, 0, peakDay).Format("Mon 2006-01-02"))
+	if p.MaxPerDay > 0 {
+		fmt.Printf("review.max_per_day = %d caps every day; the rest rolls over.\n", p.MaxPerDay)
+	}
+	return nil
+}
+
+// medianTook is the median answer time of TUI reviews (0 when none were timed).
+func medianTook(reviews []ReviewEntry) time.Duration {
+	ms := []int64{}
+	for _, e := range reviews {
+		if e.TookMS > 0 {
+			ms = append(ms, e.TookMS)
+		}
+	}
+	if len(ms) == 0 {
+		return 0
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i] < ms[j] })
+	return time.Duration(percentile(ms, 0.5)) * time.Millisecond
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultSnooze = "1d"
+
+// snoozeUntil reads a snooze span such as 4h, 2d or 1w, or a date.
+func snoozeUntil(span string, now time.Time) (time.Time, error) {
+	span = strings.TrimSpace(span)
+	bad := fmt.Errorf("bad snooze %q (try 4h, 2d, 1w or a future date)", span)
+	if t, err := time.ParseInLocation("2006-01-02", span, time.Local); err == nil {
+		if !t.After(now) {
+			return time.Time{}, bad
+		}
+		return t, nil
+	}
+	past, err := parseAge(span, now)
+	if err != nil || !past.Before(now) {
+		return time.Time{}, bad
+	}
+	return now.Add(now.Sub(past)), nil
+}
+
+// snooze pushes a card back without grading it: box, streak and the review
+// log are left alone.
+func snooze(c *Card, span string, now time.Time) error {
+	t, err := snoozeUntil(span, now)
+	if err != nil {
+		return err
+	}
+	c.NextDue = t
+	logger.Info("snooze", "id", shortID(c.ID), "next_due", t)
+	return nil
+}
+
+func (m *model) startSnooze() {
+	def := m.cfg.Review.Snooze
+	if def == "" {
+		def = defaultSnooze
+	}
+	m.snoozeIn = textinput.New()
+	m.snoozeIn.Prompt = "snooze for: "
+	m.snoozeIn.Placeholder = def + " (4h, 2d, 1w)"
+	m.snoozeIn.Focus()
+	m.snoozing = true
+}
+
+func (m model) updateSnooze(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.snoozing = false
+		m.feedback = ""
+		return m, nil
+	case "enter":
+		span := m.snoozeIn.Value()
+		if span == "" {
+			span = m.cfg.Review.Snooze
+		}
+		if span == "" {
+			span = defaultSnooze
+		}
+		now := time.Now()
+		if err := snooze(&m.cards[m.idx], span, now); err != nil {
+			m.feedback = m.th.verdict(false, err.Error())
+			return m, nil
+		}
+		_ = SaveProgress(m.cards[m.idx])
+		m.session.Snoozed++
+		m.snoozing = false
+		return m.advance()
+	}
+	var cmd tea.Cmd
+	m.snoozeIn, cmd = m.snoozeIn.Update(msg)
+	return m, cmd
+}
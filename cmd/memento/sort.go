@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// cardSorts are the orders offered by `memento list --sort` and the browser.
+var cardSorts = []string{"due", "box", "seen", "tool", "created"}
+
+func cardLess(by string) (func(a, b Card) bool, error) {
+	switch by {
+	case "due":
+		return func(a, b Card) bool { return a.NextDue.Before(b.NextDue) }, nil
+	case "box":
+		return func(a, b Card) bool { return a.Box < b.Box }, nil
+	case "seen":
+		return func(a, b Card) bool { return a.SeenCount > b.SeenCount }, nil
+	case "tool":
+		return func(a, b Card) bool { return toolOf(a) < toolOf(b) }, nil
+	case "created":
+		return func(a, b Card) bool { return a.Created.Before(b.Created) }, nil
+	}
+	return nil, fmt.Errorf("unknown sort %q (want %s)", by, strings.Join(cardSorts, "|"))
+}
+
+// sortCards orders cards in place; ties keep their stored order.
+func sortCards(cards []Card, by string, reverse bool) error {
+	less, err := cardLess(by)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		if reverse {
+			return less(cards[j], cards[i])
+		}
+		return less(cards[i], cards[j])
+	})
+	return nil
+}
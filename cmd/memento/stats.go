@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statRow is one observation in the long (tidy) export: one metric for one
+// review day and tag ("*" = all cards).
+type statRow struct {
+	Date   string  `json:"date"`
+	Tag    string  `json:"tag"`
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+}
+
+// statTally counts reviews; retention counts only reviews of cards past box 1,
+// i.e. recall after a spaced interval rather than while still learning.
+type statTally struct{ reviews, correct, mature, matureCorrect int }
+
+func (t *statTally) add(r ReviewEntry) {
+	t.reviews++
+	if r.Correct {
+		t.correct++
+	}
+	if r.BoxBefore > 1 {
+		t.mature++
+		if r.Correct {
+			t.matureCorrect++
+		}
+	}
+}
+
+func ratio(n, d int) float64 { return math.Round(float64(n)/float64(d)*1e4) / 1e4 }
+
+func (t statTally) rows(date, tag string) []statRow {
+	out := []statRow{
+		{date, tag, "reviews", float64(t.reviews)},
+		{date, tag, "correct", float64(t.correct)},
+		{date, tag, "accuracy", ratio(t.correct, t.reviews)},
+	}
+	if t.mature > 0 {
+		out = append(out, statRow{date, tag, "retention", ratio(t.matureCorrect, t.mature)})
+	}
+	return out
+}
+
+// dailyStats tallies reviews since since (zero: all) per review day and tag.
+// Reviews of deleted cards only count towards "*".
+func dailyStats(reviews []ReviewEntry, tags map[string][]string, since time.Time) []statRow {
+	type key struct{ date, tag string }
+	tally := map[key]*statTally{}
+	for _, r := range reviews {
+		if r.At.Before(since) {
+			continue
+		}
+		date := dayStart(r.At).Format("2006-01-02")
+		for _, t := range append([]string{"*"}, tags[r.CardID]...) {
+			k := key{date, t}
+			if tally[k] == nil {
+				tally[k] = &statTally{}
+			}
+			tally[k].add(r)
+		}
+	}
+	keys := make([]key, 0, len(tally))
+	for k := range tally {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].date != keys[j].date {
+			return keys[i].date < keys[j].date
+		}
+		return keys[i].tag < keys[j].tag
+	})
+	out := []statRow{}
+	for _, k := range keys {
+		out = append(out, tally[k].rows(k.date, k.tag)...)
+	}
+	return out
+}
+
+func writeStatsCSV(w io.Writer, rows []statRow) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"date", "tag", "metric", "value"})
+	for _, r := range rows {
+		_ = cw.Write([]string{r.Date, r.Tag, r.Metric, strconv.FormatFloat(r.Value, 'f', -1, 64)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	days := fs.Int("days", 0, "only the last n review days (0 = all history)")
+	export := fs.String("export", "", `write daily stats to a file ("-" = stdout) in long format: date,tag,metric,value`)
+	format := fs.String("format", "", "csv|json (default: from the --export extension, else csv)")
+	_ = fs.Parse(args)
+
+	f := *format
+	if f == "" {
+		f = "csv"
+		if strings.HasSuffix(strings.ToLower(*export), ".json") {
+			f = "json"
+		}
+	}
+	if f != "csv" && f != "json" {
+		return fmt.Errorf("unknown stats format %q (want csv or json)", f)
+	}
+	reviews, err := LoadReviews()
+	if err != nil {
+		return err
+	}
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	if *days > 0 {
+		since = dayStart(time.Now()).AddDate(0, 0, 1-*days)
+	}
+	tags := map[string][]string{}
+	for _, c := range cards {
+		tags[c.ID] = c.Tags
+	}
+	rows := dailyStats(reviews, tags, since)
+
+	if *export != "" {
+		var buf strings.Builder
+		if f == "json" {
+			b, err := json.MarshalIndent(rows, "", "  ")
+			if err != nil {
+				return err
+			}
+			buf.Write(append(b, '\n'))
+		} else if err := writeStatsCSV(&buf, rows); err != nil {
+			return err
+		}
+		if *export == "-" {
+			fmt.Print(buf.String())
+			return nil
+		}
+		if err := os.WriteFile(*export, []byte(buf.String()), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %d rows to %s\n", len(rows), *export)
+		return nil
+	}
+
+	total := map[string]*statTally{}
+	dates := map[string]bool{}
+	for _, r := range reviews {
+		if r.At.Before(since) {
+			continue
+		}
+		dates[dayStart(r.At).Format("2006-01-02")] = true
+		for _, t := range append([]string{"*"}, tags[r.CardID]...) {
+			if total[t] == nil {
+				total[t] = &statTally{}
+			}
+			total[t].add(r)
+		}
+	}
+	all := total["*"]
+	if all == nil {
+		fmt.Println("No reviews logged yet.")
+		return nil
+	}
+	fmt.Printf("%d reviews on %d days, %.0f%% correct", all.reviews, len(dates), 100*ratio(all.correct, all.reviews))
+	if all.mature > 0 {
+		fmt.Printf(", %.0f%% retention past box 1", 100*ratio(all.matureCorrect, all.mature))
+	}
+	fmt.Println()
+	delete(total, "*")
+	byReviews := make([]string, 0, len(total))
+	for t := range total {
+		byReviews = append(byReviews, t)
+	}
+	sort.Slice(byReviews, func(i, j int) bool {
+		a, b := byReviews[i], byReviews[j]
+		if total[a].reviews != total[b].reviews {
+			return total[a].reviews > total[b].reviews
+		}
+		return a < b
+	})
+	for _, t := range byReviews {
+		s := total[t]
+		ret := "-"
+		if s.mature > 0 {
+			ret = fmt.Sprintf("%.0f%%", 100*ratio(s.matureCorrect, s.mature))
+		}
+		fmt.Printf("  %-20s %5d reviews  %4.0f%% correct  retention %s\n", t, s.reviews, 100*ratio(s.correct, s.reviews), ret)
+	}
+	return nil
+}
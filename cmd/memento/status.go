@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// Due-count thresholds used to pick the bar class / colour.
+const (
+	statusWarnAt  = 10
+	statusAlertAt = 30
+)
+
+func statusClass(due int) string {
+	switch {
+	case due == 0:
+		return "done"
+	case due < statusWarnAt:
+		return "due"
+	case due < statusAlertAt:
+		return "warning"
+	default:
+		return "critical"
+	}
+}
+
+func statusTooltip(cards []Card, due int, now time.Time) string {
+	s := fmt.Sprintf("%d due of %d cards", due, len(cards))
+	if paused != nil {
+		return s + "\npaused until " + paused.Until.Local().Format("2006-01-02")
+	}
+	if due == 0 {
+		var next time.Time
+		for _, c := range cards {
+			if !c.Suspended && (next.IsZero() || c.NextDue.Before(next)) {
+				next = c.NextDue
+			}
+		}
+		if !next.IsZero() {
+			s += "\nnext: " + humanTime(next, now)
+		}
+	}
+	return s
+}
+
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	format := fs.String("format", "plain", "output format: plain|waybar|polybar|i3blocks")
+	_ = fs.Parse(args)
+
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	due := len(DueCards(cards, now))
+	class := statusClass(due)
+	tooltip := statusTooltip(cards, due, now)
+
+	switch *format {
+	case "plain":
+		fmt.Println(due)
+	case "waybar":
+		b, err := json.Marshal(map[string]any{
+			"text":    fmt.Sprintf("%d", due),
+			"alt":     class,
+			"tooltip": tooltip,
+			"class":   class,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "polybar":
+		fmt.Printf("%%{F%s}%d%%{F-}\n", statusColor(class), due)
+	case "i3blocks":
+		// full_text, short_text, color
+		fmt.Printf("%d due\n%d\n%s\n", due, due, statusColor(class))
+	default:
+		return fmt.Errorf("unknown status format %q", *format)
+	}
+	return nil
+}
+
+func statusColor(class string) string {
+	switch class {
+	case "done":
+		return "#a6e3a1"
+	case "due":
+		return "#f9e2af"
+	case "warning":
+		return "#fab387"
+	default:
+		return "#f38ba8"
+	}
+}
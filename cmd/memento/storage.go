@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"memento/pkg/storage"
+)
+
+// ReviewEntry is one graded answer in the review log.
+type ReviewEntry = storage.ReviewEntry
+
+// store is the backend chosen by config (storage.backend), json by default.
+var store storage.Storage = storage.JSON{}
+
+func LoadCards() ([]Card, error)          { return store.Load() }
+func LoadReviews() ([]ReviewEntry, error) { return store.LoadReviews() }
+func AppendReview(e ReviewEntry) error    { return store.AppendReview(e) }
+
+// updateCards applies fn to the deck as it is on disk now, holding the
+// store lock from load to save, so concurrent writers (a review, ingest from
+// the shell hook) each keep the other's changes. If fn returns nil nothing
+// is saved (a dry run, nothing to do, or fn failed).
+func updateCards(fn func([]Card) []Card) error {
+	unlock, err := store.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	cards, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if cards = fn(cards); cards == nil {
+		return nil
+	}
+	return store.Save(cards)
+}
+
+// addCards upserts incoming into the deck under the lock and reports the
+// deck size before and after.
+func addCards(incoming []Card) (before, after int, err error) {
+	err = updateCards(func(cards []Card) []Card {
+		before = len(cards)
+		cards = UpsertCards(cards, incoming)
+		after = len(cards)
+		return cards
+	})
+	return before, after, err
+}
+
+// jsonOnly guards commands that work on cards.json and its journal directly.
+func jsonOnly(cmd string) error {
+	if _, ok := store.(storage.JSON); !ok {
+		return fmt.Errorf("%s works on the json backend only (storage.backend)", cmd)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Answer strictness levels, loosest last.
+const (
+	strictExact     = "exact"
+	strictNoCase    = "case-insensitive"
+	strictFuzzy     = "fuzzy"     // case-insensitive, a typo per 5 characters
+	strictSubstring = "substring" // the expected answer appears somewhere in what was typed
+)
+
+// strictness is review.strictness, set by configure; Card.Strictness overrides it.
+var strictness = strictNoCase
+
+func checkStrictness(s string) error {
+	switch s {
+	case strictExact, strictNoCase, strictFuzzy, strictSubstring:
+		return nil
+	}
+	return fmt.Errorf("unknown strictness %q (want exact, case-insensitive, fuzzy or substring)", s)
+}
+
+func strictnessFor(c Card) string {
+	if c.Strictness != "" {
+		return c.Strictness
+	}
+	return strictness
+}
+
+// answerMatches compares one accepted answer with what was typed.
+func answerMatches(want, ans, level string) bool {
+	want, ans = strings.TrimSpace(want), strings.TrimSpace(ans)
+	if want == "" || ans == "" {
+		return false
+	}
+	switch level {
+	case strictExact:
+		return want == ans
+	case strictFuzzy:
+		w, a := strings.ToLower(want), strings.ToLower(ans)
+		return levenshtein(w, a) <= len([]rune(w))/5
+	case strictSubstring:
+		return strings.Contains(strings.ToLower(ans), strings.ToLower(want))
+	}
+	return strings.EqualFold(want, ans)
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"memento/pkg/ingest"
+)
+
+// Binaries common enough that a deck for them is worth suggesting.
+var wellKnownTools = []string{
+	"git", "kubectl", "docker", "podman", "helm", "terraform", "ansible", "aws", "gcloud", "az", "gh",
+	"ffmpeg", "imagemagick", "convert", "rg", "fd", "fzf", "jq", "yq", "awk", "sed", "find", "xargs",
+	"tar", "rsync", "ssh", "scp", "curl", "wget", "openssl", "tmux", "systemctl", "journalctl",
+	"make", "go", "cargo", "npm", "psql", "sqlite3", "nmap", "tcpdump", "strace", "lsof", "ip",
+}
+
+type suggestion struct {
+	tool, text string
+}
+
+func Suggest(cards []Card, events []CommandEvent, installed func(string) bool) []suggestion {
+	cardsPer := map[string]int{}
+	for _, c := range cards {
+		cardsPer[toolOf(c)]++
+		for _, t := range c.Tags {
+			if t != toolOf(c) {
+				cardsPer[t]++
+			}
+		}
+	}
+	usesPer := map[string]int{}
+	for _, ev := range events {
+		if f := strings.Fields(ev.Command); len(f) > 0 {
+			usesPer[f[0]]++
+		}
+	}
+	decks := map[string]string{}
+	for _, d := range bundledDeckNames() {
+		tool, _, _ := strings.Cut(d, "-")
+		decks[tool] = d
+	}
+
+	out := []suggestion{}
+	for _, tool := range wellKnownTools {
+		if !installed(tool) || cardsPer[tool] > 0 {
+			continue
+		}
+		uses := usesPer[tool]
+		switch {
+		case uses >= 5:
+			out = append(out, suggestion{tool, fmt.Sprintf(
+				"you ran %d distinct %s commands but none looked tricky — `memento remember` the ones you look up", uses, tool)})
+		case decks[tool] != "":
+			out = append(out, suggestion{tool, fmt.Sprintf("installed, zero cards — try `memento deck install %s`", decks[tool])})
+		default:
+			out = append(out, suggestion{tool, fmt.Sprintf("installed, zero cards — try `memento discover %s`", tool)})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return usesPer[out[i].tool] > usesPer[out[j].tool] })
+	return out
+}
+
+func runSuggest(args []string) error {
+	fs := flag.NewFlagSet("suggest", flag.ExitOnError)
+	_ = fs.Parse(args)
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	sugg := Suggest(cards, ParseHistory(defaultSources(), ingest.Window{}), toolInstalled)
+	if len(sugg) == 0 {
+		fmt.Println("Your deck already covers every well-known tool on this machine.")
+		return nil
+	}
+	for _, s := range sugg {
+		fmt.Printf("  %-12s %s\n", s.tool, s.text)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// theme styles the review TUI. Every theme marks answers with ✔/✘; the
+// accessible ones also vary weight/underline so colour is never the only cue.
+type theme struct {
+	Header, Prompt, Faint, Correct, Wrong lipgloss.Style
+	barFrom, barTo                        string
+}
+
+func (t theme) progress() progress.Model {
+	return progress.New(progress.WithGradient(t.barFrom, t.barTo))
+}
+
+func (t theme) verdict(ok bool, s string) string {
+	if ok {
+		return t.Correct.Render(s)
+	}
+	return t.Wrong.Render(s)
+}
+
+// Okabe–Ito blue/orange/vermillion stay distinct under deuteranopia and protanopia.
+var themes = map[string]theme{
+	"default": {
+		Header:  lipgloss.NewStyle().Bold(true),
+		Prompt:  lipgloss.NewStyle().Foreground(lipgloss.Color("212")),
+		Faint:   lipgloss.NewStyle().Faint(true),
+		Correct: lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+		Wrong:   lipgloss.NewStyle().Foreground(lipgloss.Color("203")),
+		barFrom: "#5A56E0", barTo: "#EE6FF8",
+	},
+	"high-contrast": {
+		Header:  lipgloss.NewStyle().Bold(true).Underline(true),
+		Prompt:  lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15")),
+		Faint:   lipgloss.NewStyle().Foreground(lipgloss.Color("250")),
+		Correct: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15")),
+		Wrong:   lipgloss.NewStyle().Bold(true).Reverse(true),
+		barFrom: "#FFFFFF", barTo: "#FFFFFF",
+	},
+	"deuteranopia": cvdTheme(),
+	"protanopia":   cvdTheme(),
+}
+
+func cvdTheme() theme {
+	return theme{
+		Header:  lipgloss.NewStyle().Bold(true),
+		Prompt:  lipgloss.NewStyle().Foreground(lipgloss.Color("#56B4E9")),
+		Faint:   lipgloss.NewStyle().Faint(true),
+		Correct: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#0072B2")),
+		Wrong:   lipgloss.NewStyle().Underline(true).Foreground(lipgloss.Color("#E69F00")),
+		barFrom: "#0072B2", barTo: "#56B4E9",
+	}
+}
+
+func themeNames() []string {
+	out := []string{}
+	for n := range themes {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func themeByName(name string) (theme, error) {
+	if name == "" {
+		name = "default"
+	}
+	t, ok := themes[name]
+	if !ok {
+		return theme{}, fmt.Errorf("unknown theme %q (have %v)", name, themeNames())
+	}
+	return t, nil
+}
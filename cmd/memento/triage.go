@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"memento/pkg/ingest"
+	"memento/pkg/storage"
+)
+
+// Approval: ingest scores every generated card. Cards at or above
+// ingest.accept_score join the deck, junk is rejected outright (reasons go to
+// the log), and the rest wait in staging.json for `memento triage`.
+
+// acceptScore is ingest.accept_score, set by configure (0 accepts all non-junk).
+var acceptScore = 0.5
+
+type StagedCard struct {
+	Card
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+type Staging struct {
+	Staged   []StagedCard      `json:"staged"`
+	Rejected map[string]string `json:"rejected"` // card ID → why; keeps re-ingest from bringing it back
+}
+
+func stagingPath() (string, error) {
+	d, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "staging.json"), nil
+}
+
+func loadStaging() (Staging, error) {
+	st := Staging{Rejected: map[string]string{}}
+	p, err := stagingPath()
+	if err != nil {
+		return st, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return st, fmt.Errorf("%s: %w", p, err)
+	}
+	if st.Rejected == nil {
+		st.Rejected = map[string]string{}
+	}
+	return st, nil
+}
+
+func saveStaging(st Staging) error {
+	p, err := stagingPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(p, b, 0o644)
+}
+
+// stubs stand in for staged and rejected cards during generation, so the
+// generators treat their IDs as known.
+func (st Staging) stubs() []Card {
+	out := []Card{}
+	for _, s := range st.Staged {
+		out = append(out, Card{ID: s.ID})
+	}
+	for id := range st.Rejected {
+		out = append(out, Card{ID: id})
+	}
+	return out
+}
+
+// junkReason says why a generated card can't be a useful question, or "".
+func junkReason(c Card) string {
+	if k := c.Kind(); k != "cloze" && k != comboTag {
+		return ""
+	}
+	opt, _, _ := flagValue(strings.TrimSpace(c.Answer))
+	switch {
+	case opt == "":
+		return "empty answer"
+	case strings.Contains(opt, "<") && strings.Contains(opt, ">"):
+		return "answer is a placeholder (" + opt + ")"
+	case strings.Contains(opt, "***"):
+		return "answer is redacted"
+	case opt == "|" || opt == "&&" || opt == "||" || opt == ";":
+		return "answer is an operator (" + opt + ")"
+	case opt == toolOf(c) && c.Kind() == "cloze":
+		return "answer is the tool name"
+	case !strings.Contains(c.Prompt, "_____"):
+		return "prompt has no blank"
+	}
+	return ""
+}
+
+// qualityScore rates a generated card 0..1, with the reasons behind it.
+func qualityScore(c Card) (float64, []string) {
+	if k := c.Kind(); k != "cloze" && k != comboTag {
+		return 1, nil
+	}
+	score := 0.3
+	why := []string{}
+	words := strings.Fields(c.Command)
+	opt, _, _ := flagValue(c.Answer)
+	switch {
+	case strings.HasPrefix(opt, "--"):
+		score += 0.3
+		why = append(why, "long flag")
+	case strings.HasPrefix(opt, "-"):
+		score += 0.2
+		why = append(why, "short flag")
+	case len(words) > 0 && preferSubcommands(words[0])[opt]:
+		score += 0.3
+		why = append(why, "subcommand")
+	default:
+		why = append(why, "answer is a plain word")
+	}
+	if describeFlag(words, opt) != "" {
+		score += 0.2
+		why = append(why, "documented flag")
+	}
+	toks := strings.Fields(c.Prompt)
+	ph := 0
+	for _, t := range toks {
+		if strings.Contains(t, "<") && strings.Contains(t, ">") {
+			ph++
+		}
+	}
+	if len(toks) > 0 && 2*ph > len(toks) {
+		score -= 0.2
+		why = append(why, "prompt is mostly placeholders")
+	}
+	if len(toks) > 25 {
+		score -= 0.1
+		why = append(why, "very long command")
+	}
+	return clampF(score, 0, 1), why
+}
+
+func clampF(x, lo, hi float64) float64 { return math.Min(math.Max(x, lo), hi) }
+
+// triageCards splits freshly generated cards into accepted, staged and
+// rejected (ID → reason).
+func triageCards(cards []Card) (accepted []Card, staged []StagedCard, rejected map[string]string) {
+	rejected = map[string]string{}
+	for _, c := range cards {
+		if why := junkReason(c); why != "" {
+			rejected[c.ID] = why
+			logger.Info("card rejected", "id", shortID(c.ID), "reason", why, "cmd", logCmd(c.Command))
+			continue
+		}
+		score, why := qualityScore(c)
+		if score >= acceptScore {
+			accepted = append(accepted, c)
+			continue
+		}
+		staged = append(staged, StagedCard{Card: c, Score: score, Reasons: why})
+		logger.Debug("card staged", "id", shortID(c.ID), "score", score, "reasons", why)
+	}
+	return accepted, staged, rejected
+}
+
+// generateApproved runs the generators with staged and rejected cards counted
+// as known and triages what comes out. cards picks up seen counts in place;
+// saveIngest carries them over to the deck.
+func generateApproved(srcs []ingest.Source, w ingest.Window, cards []Card, st Staging, now time.Time) ([]Card, []StagedCard, map[string]string) {
+	all := append(cards[:len(cards):len(cards)], st.stubs()...)
+	created := generateAll(srcs, w, all, now)
+	copy(cards, all[:len(cards)])
+	return triageCards(created)
+}
+
+// stage records triage results for later ingests and `memento triage`.
+func (st *Staging) stage(staged []StagedCard, rejected map[string]string) {
+	st.Staged = append(st.Staged, staged...)
+	for id, why := range rejected {
+		st.Rejected[id] = why
+	}
+}
+
+func runTriage(args []string) error {
+	fs := flag.NewFlagSet("triage", flag.ExitOnError)
+	list := fs.Bool("list", false, "list staged cards and exit")
+	acceptAll := fs.Bool("accept-all", false, "accept every staged card")
+	rejectAll := fs.Bool("reject-all", false, "reject every staged card")
+	_ = fs.Parse(args)
+
+	st, err := loadStaging()
+	if err != nil {
+		return err
+	}
+	if len(st.Staged) == 0 {
+		fmt.Printf("Nothing staged (%d cards rejected as junk so far).\n", len(st.Rejected))
+		return nil
+	}
+	sort.SliceStable(st.Staged, func(i, j int) bool { return st.Staged[i].Score > st.Staged[j].Score })
+	if *list {
+		for _, s := range st.Staged {
+			fmt.Printf("%s  %.2f  %-40s answer %-16s %s\n", shortID(s.ID), s.Score, firstLine(s.Prompt), s.Answer, strings.Join(s.Reasons, ", "))
+		}
+		return nil
+	}
+	a := asker{r: bufio.NewReader(os.Stdin), w: os.Stdout}
+	keep := []StagedCard{}
+	accepted := []Card{}
+	for i, s := range st.Staged {
+		choice := "s"
+		switch {
+		case *acceptAll:
+			choice = "a"
+		case *rejectAll:
+			choice = "r"
+		case interactive():
+			fmt.Printf("\n[%d/%d] score %.2f (%s)\n  %s\n  answer: %s\n", i+1, len(st.Staged), s.Score, strings.Join(s.Reasons, ", "), s.Prompt, s.Answer)
+			choice = strings.ToLower(a.ask("  [a]ccept, [r]eject, [s]kip, [q]uit?", "s"))
+		}
+		if choice == "q" {
+			keep = append(keep, st.Staged[i:]...)
+			break
+		}
+		switch choice {
+		case "a":
+			accepted = append(accepted, s.Card)
+		case "r":
+			st.Rejected[s.ID] = "rejected in triage"
+		default:
+			keep = append(keep, s)
+		}
+	}
+	if len(accepted) > 0 {
+		if _, _, err := addCards(accepted); err != nil {
+			return err
+		}
+		warnHook("post-ingest", accepted)
+	}
+	rejected := len(st.Staged) - len(keep) - len(accepted)
+	st.Staged = keep
+	if err := saveStaging(st); err != nil {
+		return err
+	}
+	fmt.Printf("Accepted %d, rejected %d, %d still staged.\n", len(accepted), rejected, len(keep))
+	return nil
+}
@@ -0,0 +1,639 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"memento/pkg/srs"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type model struct {
+	cfg         Config
+	cards       []Card
+	idx         int
+	input       textinput.Model
+	area        textarea.Model // multi-line input for whole-command answers
+	useArea     bool
+	progress    progress.Model
+	feedback    string
+	checking    bool
+	quit        bool
+	complete    map[string][]string // tool → answer completions (tab)
+	keys        keyMap
+	help        help.Model
+	showHelp    bool
+	session     ReviewSession
+	shownAt     time.Time // when the current card appeared (answer timing)
+	th          theme
+	practice    bool                    // blind-typing arena: no grading, boxes untouched
+	scores      []float64               // practice match scores
+	concrete    map[string]practiceFill // practice --concrete: card ID → filled command
+	snoozing    bool                    // asking how long to snooze the current card
+	snoozeIn    textinput.Model
+	flash       string          // one-off status line, e.g. "copied"
+	hinted      bool            // hint revealed for the current card
+	relearn     map[string]bool // failed this session; re-asked at the end until right
+	autoAdvance bool            // move on by itself after a correct answer
+	persist     bool            // keep session.json up to date (memento review)
+	budget      int             // graded answers left today under review.max_per_day (0 = no cap)
+	capped      bool            // budget used up: end after this card
+	editingAlt  bool            // editing the current card's alternative answers
+	altIn       textinput.Model
+	stamp       string          // store.Stamp() when the queue was last synced with the deck
+	pickUp      func(Card) bool // review.pick_up_new: admits cards that become due mid-session
+	pickUpBy    *reviewFilter   // what pickUp was built from, kept in session.json
+}
+
+// initialModel reviews queue; all is the whole deck (for completions).
+func initialModel(queue, all []Card, cfg Config) model {
+	m := model{cfg: cfg, cards: queue, help: help.New(), relearn: map[string]bool{}}
+	m.session = ReviewSession{Started: time.Now(), Queued: len(queue), Reviews: []ReviewEntry{}}
+	m.shownAt = time.Now()
+	m.keys, _ = keyMapFromConfig(cfg.Keys) // validated in configure()
+	m.th, _ = themeByName(cfg.Review.Theme)
+	m.autoAdvance = cfg.Review.AutoAdvance
+	m.stamp, _ = store.Stamp()
+	if len(m.cards) == 0 {
+		return m
+	}
+	m.input = textinput.New()
+	m.input.Placeholder = "your answer (flag/word)"
+	m.input.ShowSuggestions = true
+	m.input.KeyMap.AcceptSuggestion = m.keys.Complete
+	m.input.Focus()
+	m.area = newAnswerArea()
+	m.progress = m.th.progress()
+	m.complete = completionIndex(all)
+	m.setSuggestions()
+	m.pickInput()
+	return m
+}
+
+// newAnswerArea is a wrapping textarea where Enter submits (alt+enter / ctrl+j
+// insert a newline); ctrl+w, ctrl+u and ctrl+v come with the default keymap.
+func newAnswerArea() textarea.Model {
+	a := textarea.New()
+	a.Placeholder = "type the whole command"
+	a.ShowLineNumbers = false
+	a.CharLimit = 0
+	a.SetWidth(72)
+	a.SetHeight(3)
+	a.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+	return a
+}
+
+// plainAnswer is true for cards checked by text match (alternatives apply).
+func plainAnswer(c Card) bool { return specFor(c).plain }
+
+// wantsLongAnswer is true for cards whose answer is a whole command.
+func wantsLongAnswer(c Card) bool { return specFor(c).long }
+
+// pickInput focuses the single-line input or the textarea for the current card.
+func (m *model) pickInput() {
+	m.useArea = m.practice || wantsLongAnswer(m.cards[m.idx])
+	if !m.practice {
+		m.input.Placeholder = specFor(m.cards[m.idx]).placeholder
+		m.area.Placeholder = m.input.Placeholder
+	}
+	m.input.SetValue("")
+	m.area.Reset()
+	if m.useArea {
+		m.input.Blur()
+		m.area.Focus()
+	} else {
+		m.area.Blur()
+		m.input.Focus()
+	}
+}
+
+func (m model) answer() string {
+	if m.useArea {
+		return strings.Join(strings.Fields(m.area.Value()), " ")
+	}
+	return strings.TrimSpace(m.input.Value())
+}
+
+// setSuggestions offers tab completion from the current card's tool vocabulary.
+func (m *model) setSuggestions() {
+	c := m.cards[m.idx]
+	if !specFor(c).complete || m.practice {
+		m.input.SetSuggestions(nil)
+		return
+	}
+	m.input.SetSuggestions(m.complete[toolOf(c)])
+}
+
+func (m model) Init() tea.Cmd { return m.watchCmd() }
+
+func (m model) View() string {
+	st := lipgloss.NewStyle().Margin(1, 2)
+	if len(m.cards) == 0 {
+		return st.Render("Nothing due. You're done for today. ✨")
+	}
+	if m.showHelp {
+		title := lipgloss.NewStyle().Bold(true).Render("Keys")
+		box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1, 2)
+		return st.Render(box.Render(title + "\n\n" + m.help.FullHelpView(m.keys.FullHelp()) + "\n\n(any key to close)"))
+	}
+	c := m.cards[m.idx]
+	star := ""
+	if c.Starred {
+		star = " ★"
+	}
+	header := m.th.Header.Render(fmt.Sprintf("[%d/%d]%s Tags: %s", m.idx+1, len(m.cards), star, strings.Join(c.Tags, ", ")))
+	prompt := m.th.Prompt.Render(c.Prompt)
+	if m.practice {
+		header = m.th.Header.Render(fmt.Sprintf("[%d/%d] Practice — type the whole command (boxes untouched)", m.idx+1, len(m.cards)))
+		p := practicePrompt(c)
+		if f, ok := m.concrete[c.ID]; ok {
+			p += "\nValues: " + strings.Join(f.vals, ", ")
+		}
+		prompt = m.th.Prompt.Render(p)
+	}
+	if !m.practice {
+		fresh := 0
+		for _, o := range m.cards[m.idx:] {
+			if srs.IsNew(o) {
+				fresh++
+			}
+		}
+		header += "\n" + m.th.Faint.Render(fmt.Sprintf("%s · box %d · %s · left: %d new, %d review",
+			specFor(c).label, c.Box, reviewedLabel(c, time.Now()), fresh, len(m.cards)-m.idx-fresh))
+	}
+	if m.hinted {
+		prompt += "\n" + m.th.Faint.Render("hint: "+hintFor(c))
+		if s := samplesLine(c); s != "" {
+			prompt += "\n" + m.th.Faint.Render(s)
+		}
+	}
+	if ctx := c.Context(); m.cfg.Review.ShowContext && ctx != "" {
+		prompt += "\n" + m.th.Faint.Render(ctx)
+	}
+	bar := m.progress.ViewAs(float64(m.idx) / float64(len(m.cards)))
+	fb := m.feedback
+	hint := m.help.ShortHelpView(m.keys.answeringHelp())
+	if m.checking {
+		hint = m.help.ShortHelpView(m.keys.checkingHelp())
+		if opt, _, _ := flagValue(c.Answer); plainAnswer(c) && !m.practice {
+			if d := describeFlag(strings.Fields(c.Command), opt); d != "" {
+				fb += "\n" + m.th.Faint.Render(opt+": "+d)
+			}
+		}
+		if m.cfg.Review.ShowExample && c.Example != "" {
+			fb += "\n" + m.th.Faint.Render("e.g. "+c.Example)
+		}
+		for _, o := range c.Origins {
+			fb += "\n" + m.th.Faint.Render("from "+originLabel(o))
+		}
+	}
+	in := m.input.View()
+	if m.useArea {
+		in = m.area.View()
+	}
+	if m.flash != "" {
+		fb += "\n" + m.th.Faint.Render(m.flash)
+	}
+	if m.snoozing {
+		in = m.snoozeIn.View()
+		hint = m.th.Faint.Render("enter snooze (no grading) • esc cancel")
+	}
+	if m.editingAlt {
+		in = m.altIn.View()
+		hint = m.th.Faint.Render("enter save • esc cancel")
+	}
+	return st.Render(header + "\n\n" + prompt + "\n\n" + in + "\n\n" + bar + "\n\n" + fb + "\n" + hint)
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case advanceMsg:
+		if m.checking && m.idx == int(msg) && !m.showHelp {
+			return m.advance()
+		}
+		return m, nil
+	case watchMsg:
+		m.sync()
+		return m, m.watchCmd()
+	case tea.KeyMsg:
+		if m.showHelp {
+			m.showHelp = false
+			return m, nil
+		}
+		if m.snoozing && !key.Matches(msg, m.keys.ForceQuit) {
+			return m.updateSnooze(msg)
+		}
+		if m.editingAlt && !key.Matches(msg, m.keys.ForceQuit) {
+			return m.updateAlt(msg)
+		}
+		switch {
+		case key.Matches(msg, m.keys.ForceQuit):
+			m.quit = true
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Help) && (m.checking || m.answer() == "" || len(m.cards) == 0):
+			m.showHelp = true
+			return m, nil
+		case key.Matches(msg, m.keys.Star) && len(m.cards) > 0:
+			c := &m.cards[m.idx]
+			c.Starred = !c.Starred
+			_ = saveStar(*c)
+			return m, nil
+		case key.Matches(msg, m.keys.Hint) && !m.checking && !m.practice && len(m.cards) > 0:
+			m.hinted = true
+			return m, nil
+		case key.Matches(msg, m.keys.Snooze) && !m.checking && !m.practice && len(m.cards) > 0:
+			m.startSnooze()
+			return m, nil
+		case key.Matches(msg, m.keys.Check) && !m.checking:
+			if len(m.cards) == 0 {
+				return m, tea.Quit
+			}
+			ans := m.answer()
+			if m.practice {
+				c := m.cards[m.idx]
+				score, target := practiceScore(c, ans), c.Command
+				if f, ok := m.concrete[c.ID]; ok {
+					score, target = score*f.typed(ans), f.cmd
+				}
+				m.scores = append(m.scores, score)
+				m.feedback = m.th.verdict(score >= practicePass, practiceFeedback(score, target))
+				m.checking = true
+				m.area.Blur()
+				return m, m.autoAdvanceCmd(score >= practicePass)
+			}
+			correct := m.grade(ans)
+			m.checking = true
+			m.input.Blur()
+			m.area.Blur()
+			m.saveSession()
+			return m, m.autoAdvanceCmd(correct)
+		case key.Matches(msg, m.keys.AutoAdvance):
+			m.autoAdvance = !m.autoAdvance
+			m.flash = "auto-advance off"
+			if m.autoAdvance {
+				m.flash = fmt.Sprintf("auto-advance on (%s after a correct answer)", m.advanceDelay())
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Skip) && !m.checking && len(m.cards) > 0:
+			return m.skip()
+		case m.checking && key.Matches(msg, m.keys.AltAnswers) && plainAnswer(m.cards[m.idx]):
+			m.startAlt()
+			return m, nil
+		case m.checking && key.Matches(msg, m.keys.Copy):
+			return m.copyCommand()
+		case m.checking && key.Matches(msg, m.keys.Next):
+			return m.advance()
+		case m.checking && key.Matches(msg, m.keys.Quit):
+			m.quit = true
+			return m, tea.Quit
+		}
+		if m.checking {
+			return m, nil // answer is graded; don't edit it further
+		}
+	}
+	var cmd tea.Cmd
+	if m.useArea {
+		m.area, cmd = m.area.Update(msg)
+	} else {
+		m.input, cmd = m.input.Update(msg)
+	}
+	return m, cmd
+}
+
+// grade checks ans against the current card. The first answer is scheduled
+// and logged; a failed card then comes back at the end of the session
+// (relearning) until answered correctly, without touching its box again.
+func (m *model) grade(ans string) bool {
+	c := &m.cards[m.idx]
+	correct := checkAnswer(*c, ans)
+	score, partNote := 1.0, ""
+	if parts, multi := scoreParts(*c, ans); multi && !correct && strings.TrimSpace(ans) != "" {
+		score = partScore(parts)
+		correct = score >= partialPass
+		partNote = "\n" + m.th.Faint.Render(fmt.Sprintf("%s · %.0f%% right", partsFeedback(parts), 100*score))
+	}
+	now := time.Now()
+	if m.relearn[c.ID] {
+		m.session.Relearned++
+		if correct {
+			delete(m.relearn, c.ID)
+			m.feedback = m.th.verdict(true, feedbackLine(true, *c)+" · relearned")
+			return true
+		}
+		m.feedback = m.th.verdict(false, feedbackLine(false, *c)) + "\n" + m.th.Faint.Render("once more later this session")
+		m.cards = append(m.cards, *c)
+		return false
+	}
+	e, _ := gradeAndLog(c, ans, correct, m.hinted, score, now.Sub(m.shownAt), now)
+	m.session.Reviews = append(m.session.Reviews, e)
+	if m.budget > 0 {
+		m.budget--
+		m.capped = m.budget == 0
+	}
+	fb := feedbackLine(correct, *c)
+	if correct && m.hinted {
+		fb = "✔ Correct with a hint (box unchanged) → " + c.Answer
+	} else if correct && score < 1 {
+		fb = "✔ Mostly right (box unchanged) → " + c.Answer
+	}
+	m.feedback = m.th.verdict(correct, fb) + partNote + "\n" + m.th.Faint.Render(dueLabel(*c, now))
+	if m.capped {
+		m.feedback += "\n" + m.th.Faint.Render("daily review cap reached; this was the last card today")
+	}
+	_ = SaveProgress(*c)
+	if !correct && m.cfg.Review.Relearn && !m.capped {
+		m.relearn[c.ID] = true
+		m.cards = append(m.cards, *c)
+		m.feedback += m.th.Faint.Render(" · again later this session")
+	}
+	return correct
+}
+
+// advanceMsg fires after a correct answer when auto-advance is on; it carries
+// the card index so a manual Next in the meantime isn't doubled.
+type advanceMsg int
+
+func (m model) advanceDelay() time.Duration {
+	if d := time.Duration(m.cfg.Review.AutoAdvanceDelay); d > 0 {
+		return d
+	}
+	return 800 * time.Millisecond
+}
+
+func (m model) autoAdvanceCmd(correct bool) tea.Cmd {
+	if !correct || !m.autoAdvance {
+		return nil
+	}
+	idx := m.idx
+	return tea.Tick(m.advanceDelay(), func(time.Time) tea.Msg { return advanceMsg(idx) })
+}
+
+// skip moves the current card to the end of the session without grading it.
+func (m model) skip() (tea.Model, tea.Cmd) {
+	if m.idx == len(m.cards)-1 {
+		m.flash = "last card; nothing to skip to"
+		return m, nil
+	}
+	c := m.cards[m.idx]
+	m.cards = append(append(m.cards[:m.idx:m.idx], m.cards[m.idx+1:]...), c)
+	m.session.Skipped++
+	m.shownAt = time.Now()
+	m.feedback = ""
+	m.flash = ""
+	m.hinted = false
+	m.setSuggestions()
+	m.pickInput()
+	m.saveSession()
+	return m, nil
+}
+
+// copyCommand puts the card's command on the clipboard so it can be run
+// right away, falling back to an OSC 52 request to the terminal.
+func (m model) copyCommand() (tea.Model, tea.Cmd) {
+	cmd := m.cards[m.idx].Command
+	if cmd == "" {
+		cmd = m.cards[m.idx].Answer
+	}
+	err := writeClipboard(cmd)
+	switch {
+	case err == nil:
+		m.flash = "copied: " + cmd
+	case errors.Is(err, errNoClipboard):
+		m.flash = "sent to the terminal clipboard: " + cmd
+		return m, func() tea.Msg {
+			fmt.Fprint(os.Stderr, osc52(cmd)) // stderr: not interleaved with the renderer's stdout writes
+			return nil
+		}
+	default:
+		m.flash = "copy failed: " + err.Error()
+	}
+	return m, nil
+}
+
+// advance shows the next card, or ends the session after the last one.
+func (m model) advance() (tea.Model, tea.Cmd) {
+	if m.idx == len(m.cards)-1 || m.capped {
+		if m.persist {
+			_ = clearSession() // queue finished; anything left after a cap is still due tomorrow
+		}
+		return m, tea.Quit
+	}
+	m.idx++
+	m.shownAt = time.Now()
+	m.feedback = ""
+	m.flash = ""
+	m.hinted = false
+	m.checking = false
+	m.setSuggestions()
+	m.pickInput()
+	m.saveSession()
+	return m, nil
+}
+
+func checkAnswer(c Card, ans string) bool {
+	if ans == "" {
+		return false
+	}
+	return specFor(c).check(c, ans)
+}
+
+// hintFor is the card's own hint, or the start of the answer when the hint is generic.
+func hintFor(c Card) string {
+	if c.Hint != "" && c.Hint != genericHint {
+		return c.Hint
+	}
+	opt, _, _ := flagValue(c.Answer)
+	a := []rune(opt)
+	n := len(a) - len([]rune(strings.TrimLeft(opt, "-"))) + 1
+	h := fmt.Sprintf("starts with %q (%d characters)", string(a[:n]), len(a))
+	if n >= len(a) {
+		h = fmt.Sprintf("%d characters", len(a))
+	}
+	if d := describeFlag(strings.Fields(c.Command), opt); d != "" {
+		return d + "; " + h
+	}
+	return h
+}
+
+// samplesLine shows a stored value for each placeholder in the prompt:
+// "<PATH> e.g. ./build/output · <NS> e.g. prod".
+func samplesLine(c Card) string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, t := range strings.Fields(c.Prompt) {
+		if v, ok := c.Samples[t]; ok && !seen[t] {
+			seen[t] = true
+			out = append(out, t+" e.g. "+v)
+		}
+	}
+	return strings.Join(out, " · ")
+}
+
+func feedbackLine(ok bool, c Card) string {
+	ans := c.Answer
+	also := c.AltAnswers
+	if c.AnswerPattern != "" {
+		also = append(also[:len(also):len(also)], "anything matching "+c.AnswerPattern)
+	}
+	if len(also) > 0 {
+		ans += " (also " + strings.Join(also, ", ") + ")"
+	}
+	if ok {
+		return "✔ Correct → " + ans
+	}
+	return "✘ Nope. Correct: " + ans
+}
+
+func runReview(args []string) error {
+	fs := flag.NewFlagSet("review", flag.ExitOnError)
+	host := fs.String("host", "", "only review cards whose command was seen on this host")
+	skipMissing := fs.Bool("skip-missing-tools", false, "hide cards for tools not installed here (also review.skip_missing_tools)")
+	mode := fs.String("mode", "all", "all|sequence|pipeline|comprehension|danger|combo (review only that card kind)")
+	id := fs.String("id", "", "review just this card (ID prefix), due or not")
+	query := fs.String("query", "", `only review due cards matching a query, e.g. "tag:git box:<3"`)
+	resume := fs.Bool("resume", false, "resume the unfinished session without asking")
+	fresh := fs.Bool("fresh", false, "discard an unfinished session and build a new queue")
+	_ = fs.Parse(args)
+	q, err := ParseQuery(*query)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	if paused != nil && *id == "" {
+		fmt.Printf("Reviews are paused until %s (memento resume to end early).\n", paused.Until.Local().Format("Mon 2006-01-02"))
+		return nil
+	}
+	tutorial := false
+	if !cfg.Review.SkipTutorial {
+		if tutorial, err = installTutorial(); err != nil {
+			return err
+		}
+	}
+	all, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	if *id != "" {
+		i, err := findCard(all, *id)
+		if err != nil {
+			return err
+		}
+		return RunTUI(initialModel([]Card{all[i]}, all, cfg))
+	}
+	budget := 0
+	if limit := cfg.Review.MaxPerDay; limit > 0 {
+		done, err := reviewsToday(time.Now())
+		if err != nil {
+			return err
+		}
+		if done >= limit {
+			fmt.Printf("Daily cap reached: %d reviews today (review.max_per_day = %d). The rest rolls over to tomorrow.\n", done, limit)
+			return nil
+		}
+		budget = limit - done
+	}
+	saved, err := loadSession()
+	if err != nil {
+		return err
+	}
+	if saved != nil {
+		if offerResume(*saved, *resume, *fresh) {
+			m := resumeModel(*saved, all, cfg)
+			m.budget = budget
+			return RunTUI(m)
+		}
+		if err := clearSession(); err != nil {
+			return err
+		}
+	}
+	switch *mode {
+	case "all", sequenceTag, pipelineTag, comprehensionTag, dangerTag, comboTag:
+	default:
+		return fmt.Errorf("unknown review mode %q", *mode)
+	}
+	filter := reviewFilter{Query: *query, Host: *host, SkipMissing: *skipMissing, Mode: *mode}
+	admit := filter.admit(q, cfg)
+	cards := filterCards(all, admit)
+	due := srs.LimitNew(DueCards(cards, time.Now()), discoverTag, cfg.Discover.NewPerSession)
+	queue := srs.PinnedFirst(cards, srs.MixNew(due, cfg.Review.NewPerSession, cfg.Review.NewPosition))
+	if tutorial { // teach the TUI before anything else
+		tut := "deck/" + tutorialDeck
+		queue = append(filterCards(queue, func(c Card) bool { return hasTag(c, tut) }),
+			filterCards(queue, func(c Card) bool { return !hasTag(c, tut) })...)
+	}
+	m := initialModel(queue, all, cfg)
+	m.persist = true
+	m.budget = budget
+	if cfg.Review.PickUpNew {
+		m.pickUp, m.pickUpBy = admit, &filter
+	}
+	return RunTUI(m)
+}
+
+func RunTUI(m model) error {
+	p := tea.NewProgram(m)
+	quietStderr.Store(true)
+	final, err := p.Run()
+	quietStderr.Store(false)
+	if err != nil {
+		return err
+	}
+	if s := final.(model).summary(); s.Reviewed > 0 || s.Snoozed > 0 {
+		fmt.Println(s)
+		warnHook("post-review", s)
+	}
+	return nil
+}
+
+func (m model) summary() ReviewSession {
+	s := m.session
+	s.Ended = time.Now()
+	s.Reviewed = len(s.Reviews)
+	for _, e := range s.Reviews {
+		if e.Correct {
+			s.Correct++
+		}
+	}
+	s.Quit = m.quit
+	s.Capped = m.capped
+	s.timings()
+	return s
+}
+
+// saveStar stores only the star, so practice runs don't persist anything else.
+func saveStar(c Card) error {
+	return updateCards(func(cards []Card) []Card {
+		if i, err := findCard(cards, c.ID); err == nil {
+			cards[i].Starred = c.Starred
+		}
+		return cards
+	})
+}
+
+// SaveProgress writes a reviewed card back into the current deck. A card
+// deleted or merged away by another process meanwhile stays gone.
+func SaveProgress(updated Card) error {
+	return updateCards(func(cards []Card) []Card {
+		for i := range cards {
+			if cards[i].ID == updated.ID {
+				cards[i] = updated
+			}
+		}
+		return cards
+	})
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"memento/pkg/storage"
+)
+
+// undoKeep is how many destructive commands memento undo can step back through.
+const undoKeep = 20
+
+// cardChange is one card before and after a command; nil means it wasn't there.
+type cardChange struct {
+	ID     string `json:"id"`
+	Pos    int    `json:"pos"` // index in the deck before the change, to put a restored card back in place
+	Before *Card  `json:"before,omitempty"`
+	After  *Card  `json:"after,omitempty"`
+}
+
+// undoEntry is what one destructive command (delete, prune, bulk, tag, a
+// merge) changed: enough to revert it, and to reapply it after that.
+type undoEntry struct {
+	At      time.Time              `json:"at"`
+	What    string                 `json:"what"`
+	Changes []cardChange           `json:"changes"`
+	Moved   map[string]string      `json:"moved,omitempty"`   // merges: old ID → the card it went into
+	Reviews map[string][]time.Time `json:"reviews,omitempty"` // merges: old ID → when its moved reviews were logged
+	Undone  bool                   `json:"undone,omitempty"`
+}
+
+func undoPath() (string, error) {
+	d, err := storage.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "undo.json"), nil
+}
+
+func loadUndo() ([]undoEntry, error) {
+	p, err := undoPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var es []undoEntry
+	if err := json.Unmarshal(b, &es); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return es, nil
+}
+
+func saveUndo(es []undoEntry) error {
+	p, err := undoPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(es)
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(p, b, 0o644)
+}
+
+// saveUndoable is updateCards for destructive commands: it applies fn to a
+// copy of the deck loaded under the store lock, saves the result and records
+// the difference as e, so memento undo can revert it. If fn returns nil
+// nothing is saved. Anything undone and not redone is forgotten, as in an
+// editor.
+func saveUndoable(e undoEntry, fn func([]Card) []Card) error {
+	unlock, err := store.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	old, err := store.Load()
+	if err != nil {
+		return err
+	}
+	cards := fn(slices.Clone(old))
+	if cards == nil {
+		return nil
+	}
+	e.At, e.Changes = time.Now(), diffCards(old, cards)
+	if len(e.Changes) > 0 {
+		es, err := loadUndo()
+		if err != nil {
+			return err
+		}
+		es = slices.DeleteFunc(es, func(x undoEntry) bool { return x.Undone })
+		es = append(es, e)
+		if len(es) > undoKeep {
+			es = es[len(es)-undoKeep:]
+		}
+		if err := saveUndo(es); err != nil {
+			return fmt.Errorf("undo journal: %w", err)
+		}
+	}
+	return store.Save(cards)
+}
+
+func sameCard(a, b Card) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return bytes.Equal(x, y)
+}
+
+// diffCards lists the cards that differ between old and cards.
+func diffCards(old, cards []Card) []cardChange {
+	pos := map[string]int{}
+	for i, c := range old {
+		pos[c.ID] = i
+	}
+	out := []cardChange{}
+	seen := map[string]bool{}
+	for i, c := range cards {
+		seen[c.ID] = true
+		j, ok := pos[c.ID]
+		switch {
+		case !ok:
+			out = append(out, cardChange{ID: c.ID, Pos: i, After: &c})
+		case !sameCard(old[j], c):
+			b := old[j]
+			out = append(out, cardChange{ID: c.ID, Pos: j, Before: &b, After: &c})
+		}
+	}
+	for j, c := range old {
+		if !seen[c.ID] {
+			out = append(out, cardChange{ID: c.ID, Pos: j, Before: &c})
+		}
+	}
+	return out
+}
+
+// applyChanges moves the deck from one side of the changes to the other:
+// back to Before when undoing, forward to After when redoing. Cards that
+// changed again since (reviewed, re-ingested) are left alone unless force
+// is set, and counted in skipped.
+func applyChanges(deck []Card, changes []cardChange, redo, force bool) (out []Card, skipped int) {
+	idx := map[string]int{}
+	for i, c := range deck {
+		idx[c.ID] = i
+	}
+	drop := map[string]bool{}
+	var inserts []cardChange
+	for _, ch := range changes {
+		from, to := ch.After, ch.Before
+		if redo {
+			from, to = to, from
+		}
+		i, ok := idx[ch.ID]
+		if !force && (ok != (from != nil) || ok && !sameCard(deck[i], *from)) {
+			skipped++
+			continue
+		}
+		switch {
+		case to == nil:
+			drop[ch.ID] = ok
+		case ok:
+			deck[i] = *to
+		default:
+			inserts = append(inserts, cardChange{ID: ch.ID, Pos: ch.Pos, After: to})
+		}
+	}
+	out = slices.DeleteFunc(deck, func(c Card) bool { return drop[c.ID] })
+	sort.SliceStable(inserts, func(i, j int) bool { return inserts[i].Pos < inserts[j].Pos })
+	for _, ch := range inserts {
+		out = slices.Insert(out, min(ch.Pos, len(out)), *ch.After)
+	}
+	return out, skipped
+}
+
+// unmerge puts merged cards' tombstones and logged reviews back (or, when
+// redoing, moves them into the survivors again).
+func unmerge(e undoEntry, redo bool) error {
+	st, err := loadStaging()
+	if err != nil {
+		return err
+	}
+	for old, id := range e.Moved {
+		if redo {
+			st.Rejected[old] = "merged into " + shortID(id)
+		} else if strings.HasPrefix(st.Rejected[old], "merged into ") {
+			delete(st.Rejected, old)
+		}
+	}
+	if err := saveStaging(st); err != nil {
+		return err
+	}
+	reviews, err := LoadReviews()
+	if err != nil {
+		return err
+	}
+	n := 0
+	for i, r := range reviews {
+		for old, times := range e.Reviews {
+			from, to := e.Moved[old], old
+			if redo {
+				from, to = to, from
+			}
+			if r.CardID == from && slices.ContainsFunc(times, r.At.Equal) {
+				reviews[i].CardID = to
+				n++
+				break
+			}
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return store.SaveReviews(reviews)
+}
+
+// changeCounts sums up changes as seen going in the given direction.
+func changeCounts(changes []cardChange, redo bool) string {
+	restored, removed, reverted := 0, 0, 0
+	for _, ch := range changes {
+		from, to := ch.After, ch.Before
+		if redo {
+			from, to = to, from
+		}
+		switch {
+		case from == nil:
+			restored++
+		case to == nil:
+			removed++
+		default:
+			reverted++
+		}
+	}
+	verb := "restore"
+	if redo {
+		verb = "re-add"
+	}
+	return fmt.Sprintf("%s %d, remove %d, change %d cards", verb, restored, removed, reverted)
+}
+
+func runUndo(args []string) error { return stepUndo("undo", false, args) }
+func runRedo(args []string) error { return stepUndo("redo", true, args) }
+
+// stepUndo reverts the last destructive command still in effect, or with
+// redo reapplies the last one undone.
+func stepUndo(name string, redo bool, args []string) error {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	list := fs.Bool("list", false, "show what can be undone and redone")
+	dry := fs.Bool("dry-run", false, "only show what would change")
+	force := fs.Bool("force", false, "also overwrite cards changed since (e.g. reviewed), losing those changes")
+	_ = fs.Parse(args)
+
+	unlock, err := store.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	es, err := loadUndo()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if *list {
+		if len(es) == 0 {
+			fmt.Println("Nothing to undo.")
+		}
+		for i := len(es) - 1; i >= 0; i-- {
+			e, state := es[i], "undo"
+			if e.Undone {
+				state = "redo"
+			}
+			fmt.Printf("  %-4s %-12s %-40s %d cards\n", state, humanTime(e.At, now), e.What, len(e.Changes))
+		}
+		return nil
+	}
+	i := slices.IndexFunc(es, func(e undoEntry) bool { return e.Undone }) // undone entries are a suffix
+	if i < 0 {
+		i = len(es)
+	}
+	if !redo {
+		i--
+	}
+	if i < 0 || i >= len(es) {
+		fmt.Printf("Nothing to %s.\n", name)
+		return nil
+	}
+	e := es[i]
+	fmt.Printf("%s: %s (%s): %s\n", name, e.What, humanTime(e.At, now), changeCounts(e.Changes, redo))
+	if *dry {
+		return nil
+	}
+	deck, err := store.Load()
+	if err != nil {
+		return err
+	}
+	deck, skipped := applyChanges(deck, e.Changes, redo, *force)
+	if err := store.Save(deck); err != nil {
+		return err
+	}
+	es[i].Undone = !redo
+	if err := saveUndo(es); err != nil {
+		return fmt.Errorf("cards saved, but the undo journal was not updated: %w", err)
+	}
+	if len(e.Moved) > 0 {
+		if err := unmerge(e, redo); err != nil {
+			return fmt.Errorf("cards saved, but merged IDs and reviews were not moved back: %w", err)
+		}
+	}
+	logger.Info(name, "what", e.What, "changes", len(e.Changes), "skipped", skipped)
+	if skipped > 0 {
+		fmt.Printf("%d cards changed since and were left alone (--force to overwrite them).\n", skipped)
+	}
+	fmt.Printf("Done. Total: %d\n", len(deck))
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"memento/pkg/storage"
+)
+
+func deckOf(ids ...string) []Card {
+	out := []Card{}
+	for _, id := range ids {
+		out = append(out, Card{ID: id, Command: "cmd " + id, Tags: []string{"t"}, Box: 1})
+	}
+	return out
+}
+
+func idsOf(deck []Card) string {
+	ids := []string{}
+	for _, c := range deck {
+		ids = append(ids, c.ID+":"+strings.Join(c.Tags, "+"))
+	}
+	return strings.Join(ids, " ")
+}
+
+func retagged(deck []Card, ids ...string) []Card {
+	for i := range deck {
+		if slices.Contains(ids, deck[i].ID) {
+			deck[i].Tags = []string{"t", "new"}
+		}
+	}
+	return deck
+}
+
+func TestUndoRoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after []Card
+		changes       int
+	}{
+		{"nothing", deckOf("a", "b"), deckOf("a", "b"), 0},
+		{"delete middle", deckOf("a", "b", "c"), deckOf("a", "c"), 1},
+		{"delete first and last", deckOf("a", "b", "c", "d"), deckOf("b", "c"), 2},
+		{"delete all", deckOf("a", "b"), deckOf(), 2},
+		{"add", deckOf("a"), deckOf("a", "b"), 1},
+		{"retag", deckOf("a", "b", "c"), retagged(deckOf("a", "b", "c"), "a", "c"), 2},
+		{"merge: retag one, drop another", deckOf("a", "b", "c"), retagged(deckOf("a", "c"), "c"), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := diffCards(tt.before, tt.after)
+			if len(changes) != tt.changes {
+				t.Fatalf("%d changes, want %d: %+v", len(changes), tt.changes, changes)
+			}
+			undone, skipped := applyChanges(slices.Clone(tt.after), changes, false, false)
+			if skipped != 0 || idsOf(undone) != idsOf(tt.before) {
+				t.Errorf("undo: %q (skipped %d), want %q", idsOf(undone), skipped, idsOf(tt.before))
+			}
+			redone, skipped := applyChanges(undone, changes, true, false)
+			if skipped != 0 || idsOf(redone) != idsOf(tt.after) {
+				t.Errorf("redo: %q (skipped %d), want %q", idsOf(redone), skipped, idsOf(tt.after))
+			}
+		})
+	}
+}
+
+func TestUndoChangedSince(t *testing.T) {
+	old := deckOf("a", "b", "c")
+	now := retagged(deckOf("a", "b", "c"), "b")
+	changes := diffCards(old, now)
+
+	reviewed := slices.Clone(now)
+	reviewed[1].Box = 3 // reviewed after the tag command
+	out, skipped := applyChanges(slices.Clone(reviewed), changes, false, false)
+	if skipped != 1 || out[1].Box != 3 || len(out[1].Tags) != 2 {
+		t.Errorf("without force: skipped %d, card %+v; want it left alone", skipped, out[1])
+	}
+	out, skipped = applyChanges(slices.Clone(reviewed), changes, false, true)
+	if skipped != 0 || out[1].Box != 1 || len(out[1].Tags) != 1 {
+		t.Errorf("with force: skipped %d, card %+v; want it reverted", skipped, out[1])
+	}
+
+	readded := append(deckOf("a", "c"), Card{ID: "b"}) // deleted, then ingested again
+	changes = diffCards(deckOf("a", "b", "c"), deckOf("a", "c"))
+	if out, skipped := applyChanges(readded, changes, false, false); skipped != 1 || len(out) != 3 {
+		t.Errorf("re-added card: skipped %d, deck %q", skipped, idsOf(out))
+	}
+}
+
+func TestSaveUndoable(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	defer func(s storage.Storage) { store = s }(store)
+	mem := storage.NewMemory(deckOf("a", "b", "c"))
+	store = mem
+
+	// fn sees the deck as stored now, not a caller's older copy
+	if err := mem.Save(deckOf("a", "b", "c", "d")); err != nil {
+		t.Fatal(err)
+	}
+	err := saveUndoable(undoEntry{What: "delete b"}, func(cards []Card) []Card {
+		return slices.DeleteFunc(cards, func(c Card) bool { return c.ID == "b" })
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deck, _ := mem.Load(); idsOf(deck) != idsOf(deckOf("a", "c", "d")) {
+		t.Errorf("after delete: %q", idsOf(deck))
+	}
+	es, err := loadUndo()
+	if err != nil || len(es) != 1 || len(es[0].Changes) != 1 || es[0].Changes[0].ID != "b" || es[0].Changes[0].Pos != 1 {
+		t.Fatalf("undo journal: %+v, %v", es, err)
+	}
+
+	if err := saveUndoable(undoEntry{What: "nothing"}, func([]Card) []Card { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if es, _ := loadUndo(); len(es) != 1 {
+		t.Errorf("a nil result was journaled: %+v", es)
+	}
+
+	if err := stepUndo("undo", false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if deck, _ := mem.Load(); idsOf(deck) != idsOf(deckOf("a", "b", "c", "d")) {
+		t.Errorf("after undo: %q", idsOf(deck))
+	}
+}
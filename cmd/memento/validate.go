@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"memento/pkg/srs"
+	"memento/pkg/storage"
+)
+
+// problem is one validation finding; fix repairs it in place (nil = manual).
+type problem struct {
+	idx int
+	at  string // "#12 3f9a1c2b"
+	msg string
+	fix func(*Card)
+}
+
+// cardFields are the JSON keys Card knows about.
+func cardFields() map[string]bool {
+	out := map[string]bool{}
+	t := reflect.TypeOf(Card{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		out[name] = true
+	}
+	return out
+}
+
+// validateCards decodes raw cards.json bytes card by card, so one bad entry
+// doesn't hide the rest. Cards that fail to decode come back as nil entries.
+func validateCards(b []byte, now time.Time) ([]*Card, []problem, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(b, &raws); err != nil {
+		return nil, nil, fmt.Errorf("cards.json is not a JSON array: %w", err)
+	}
+	known := cardFields()
+	cards := make([]*Card, len(raws))
+	probs := []problem{}
+	seen := map[string]int{}
+	for i, raw := range raws {
+		at := fmt.Sprintf("#%d", i)
+		var c Card
+		if err := json.Unmarshal(raw, &c); err != nil {
+			probs = append(probs, problem{idx: i, at: at, msg: "does not match the card schema: " + err.Error()})
+			continue
+		}
+		cards[i] = &c
+		at += " " + shortID(c.ID)
+		add := func(msg string, fix func(*Card)) { probs = append(probs, problem{i, at, msg, fix}) }
+
+		var fields map[string]json.RawMessage
+		_ = json.Unmarshal(raw, &fields)
+		unknown := []string{}
+		for k := range fields {
+			if !known[k] {
+				unknown = append(unknown, k)
+			}
+		}
+		sort.Strings(unknown)
+		if len(unknown) > 0 {
+			add("unknown fields: "+strings.Join(unknown, ", "), func(*Card) {}) // dropped on save
+		}
+
+		if c.ID == "" {
+			add("empty id", nil)
+		} else if j, dup := seen[c.ID]; dup {
+			add(fmt.Sprintf("duplicate id (also #%d)", j), func(*Card) {}) // merged by dedupeCards
+		} else {
+			seen[c.ID] = i
+		}
+		regen := func(c *Card) { c.Prompt, c.Answer, c.Hint = cloze(c.Command) }
+		canRegen := c.Command != "" && c.Kind() == "cloze"
+		if strings.TrimSpace(c.Answer) == "" {
+			add("empty answer", ifFix(canRegen, regen))
+		}
+		if strings.TrimSpace(c.Prompt) == "" {
+			add("empty prompt", ifFix(canRegen, regen))
+		} else if c.Kind() == "cloze" && !hasTag(c, "anki") && !strings.Contains(c.Prompt, "_____") {
+			add("cloze prompt has no blank", ifFix(canRegen, regen))
+		}
+		if c.Strictness != "" && checkStrictness(c.Strictness) != nil {
+			add(checkStrictness(c.Strictness).Error(), func(c *Card) { c.Strictness = "" })
+		}
+		if c.AnswerPattern != "" {
+			if _, err := compilePattern(c.AnswerPattern); err != nil {
+				add(err.Error(), func(c *Card) { c.AnswerPattern = "" })
+			}
+		}
+		if c.Box < 1 || c.Box > srs.MaxBox() {
+			add(fmt.Sprintf("box %d outside 1..%d", c.Box, srs.MaxBox()), func(c *Card) { c.Box = min(max(c.Box, 1), srs.MaxBox()) })
+		}
+		if impossibleDate(c.NextDue, now) {
+			add("impossible next_due "+c.NextDue.Format(time.RFC3339), func(c *Card) { c.NextDue = now })
+		}
+		if c.LastReviewed.After(now.Add(time.Hour)) {
+			add("last_reviewed is in the future", func(c *Card) { c.LastReviewed = time.Time{} })
+		}
+		if c.TimesSeen < 0 || c.SeenCount < 0 || c.Streak < 0 {
+			add("negative counter", func(c *Card) {
+				c.TimesSeen, c.SeenCount, c.Streak = max(c.TimesSeen, 0), max(c.SeenCount, 0), max(c.Streak, 0)
+			})
+		}
+	}
+	return cards, probs, nil
+}
+
+func ifFix(ok bool, fix func(*Card)) func(*Card) {
+	if ok {
+		return fix
+	}
+	return nil
+}
+
+func impossibleDate(t, now time.Time) bool {
+	return t.IsZero() || t.Year() < 2000 || t.After(now.AddDate(10, 0, 0))
+}
+
+// dedupeCards keeps the most recently reviewed copy of each ID.
+func dedupeCards(cards []Card) []Card {
+	idx := map[string]int{}
+	out := []Card{}
+	for _, c := range cards {
+		j, ok := idx[c.ID]
+		if !ok {
+			idx[c.ID] = len(out)
+			out = append(out, c)
+			continue
+		}
+		keep := out[j]
+		if c.LastReviewed.After(keep.LastReviewed) {
+			keep, c = c, keep
+		}
+		for _, o := range c.Origins {
+			keep.Origins = mergeOrigin(keep.Origins, o)
+		}
+		keep.Tags = union(keep.Tags, c.Tags)
+		out[j] = keep
+	}
+	return out
+}
+
+func runValidate(args []string) error {
+	if err := jsonOnly("validate"); err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "repair what can be repaired (writes cards.json.bak first)")
+	_ = fs.Parse(args)
+	if *fix {
+		// held from read to save like updateCards, which can't be used here:
+		// a deck that needs fixing may not load
+		unlock, err := store.Lock()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	p, err := storage.CardsPath()
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		fmt.Println("No cards.json yet.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	cards, probs, err := validateCards(b, now)
+	if err != nil {
+		return err
+	}
+	manual := 0
+	for _, pr := range probs {
+		mark := "fixable"
+		if pr.fix == nil {
+			mark = "manual"
+			manual++
+		}
+		fmt.Printf("%-20s %-8s %s\n", pr.at, mark, pr.msg)
+	}
+	if len(probs) == 0 {
+		fmt.Printf("%s: %d cards, no problems\n", p, len(cards))
+		return nil
+	}
+	if !*fix {
+		return fmt.Errorf("%d problems (%d fixable with --fix)", len(probs), len(probs)-manual)
+	}
+	fixed := []Card{}
+	for _, c := range cards {
+		if c == nil {
+			return fmt.Errorf("some cards don't match the schema; fix those by hand first")
+		}
+	}
+	for _, pr := range probs {
+		if pr.fix != nil {
+			pr.fix(cards[pr.idx])
+		}
+	}
+	for _, c := range cards {
+		fixed = append(fixed, *c)
+	}
+	if err := os.WriteFile(p+".bak", b, 0o644); err != nil {
+		return err
+	}
+	if err := store.Save(dedupeCards(fixed)); err != nil {
+		return err
+	}
+	fmt.Printf("Fixed %d problems (backup: %s.bak)\n", len(probs)-manual, p)
+	if manual > 0 {
+		return fmt.Errorf("%d problems need fixing by hand", manual)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"memento/pkg/storage"
+)
+
+// Release builds set these with
+// -ldflags "-X main.version=v1.2.3 -X main.commit=abc1234 -X main.buildDate=2025-01-31T12:00:00Z".
+// Otherwise they come from the module and VCS stamps Go embeds (go install, go build in a checkout).
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// BuildInfo is what memento version prints and exports carry.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a dirty tree
+	BuildDate string `json:"build_date,omitempty"`
+	Schema    int    `json:"schema"` // storage.SchemaVersion
+	Go        string `json:"go"`
+	Platform  string `json:"platform"`
+}
+
+func buildInfo() BuildInfo {
+	bi := BuildInfo{Version: version, Commit: commit, BuildDate: buildDate, Schema: storage.SchemaVersion,
+		Go: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return bi
+	}
+	if bi.Version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		bi.Version = info.Main.Version
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if bi.Commit == "" {
+				bi.Commit = s.Value
+			}
+		case "vcs.time":
+			if bi.BuildDate == "" {
+				bi.BuildDate = s.Value
+			}
+		case "vcs.modified":
+			bi.Modified = s.Value == "true"
+		}
+	}
+	return bi
+}
+
+// String is the one-line form, e.g. "memento v1.2.3 (abc1234, 2025-01-31, schema 1)".
+func (b BuildInfo) String() string {
+	s := "memento " + b.Version + " ("
+	if b.Commit != "" {
+		c := b.Commit[:min(len(b.Commit), 7)]
+		if b.Modified {
+			c += "+dirty"
+		}
+		s += c + ", "
+	}
+	if t, err := time.Parse(time.RFC3339, b.BuildDate); err == nil {
+		s += t.UTC().Format("2006-01-02") + ", "
+	} else if b.BuildDate != "" {
+		s += b.BuildDate + ", "
+	}
+	return s + fmt.Sprintf("schema %d)", b.Schema)
+}
+
+func runVersion(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the build metadata as JSON")
+	_ = fs.Parse(args)
+	bi := buildInfo()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(bi)
+	}
+	fmt.Println(bi)
+	fmt.Printf("%s %s\n", bi.Go, bi.Platform)
+	return nil
+}
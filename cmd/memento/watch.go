@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchEvery is how often a review checks the store for saves by other processes.
+const watchEvery = 2 * time.Second
+
+// watchMsg asks the review to look for outside changes to the deck.
+type watchMsg struct{}
+
+func (m model) watchCmd() tea.Cmd {
+	if !m.persist {
+		return nil
+	}
+	return tea.Tick(watchEvery, func(time.Time) tea.Msg { return watchMsg{} })
+}
+
+// sync reloads the deck after another process saved it (ingest, the shell
+// hook, merge-cards…): queued cards pick up the changes, deleted or
+// suspended ones drop out, and with pickUp set cards that became due join
+// the end of the queue. Cards already answered are left alone.
+func (m *model) sync() {
+	stamp, err := store.Stamp()
+	if err != nil || stamp == m.stamp || len(m.cards) == 0 {
+		return
+	}
+	all, err := LoadCards()
+	if err != nil {
+		return
+	}
+	m.stamp = stamp
+	byID := map[string]Card{}
+	for _, c := range all {
+		byID[c.ID] = c
+	}
+	queue := append([]Card{}, m.cards[:m.idx]...)
+	for i, c := range m.cards[m.idx:] {
+		fresh, ok := byID[c.ID]
+		switch {
+		case i == 0 && (m.checking || !ok):
+			queue = append(queue, c) // on screen: keep it whatever happened
+		case !ok || fresh.Suspended:
+		default:
+			queue = append(queue, fresh)
+		}
+	}
+	added := 0
+	if m.pickUp != nil && !m.capped {
+		seen := map[string]bool{}
+		for _, c := range queue {
+			seen[c.ID] = true
+		}
+		for _, e := range m.session.Reviews {
+			seen[e.CardID] = true
+		}
+		for _, c := range DueCards(all, time.Now()) {
+			if !seen[c.ID] && m.pickUp(c) {
+				queue = append(queue, c)
+				added++
+			}
+		}
+	}
+	m.cards = queue
+	if added > 0 {
+		m.flash = fmt.Sprintf("%d newly due cards added to this session", added)
+	}
+	m.saveSession()
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// lapseStat counts reviews and failures for one card or group of cards.
+type lapseStat struct {
+	Key     string
+	Reviews int
+	Lapses  int
+	wrong   map[string]int // wrong answer → times given
+	cardID  string
+}
+
+func (s lapseStat) rate() float64 {
+	if s.Reviews == 0 {
+		return 0
+	}
+	return float64(s.Lapses) / float64(s.Reviews)
+}
+
+// subcommandOf is "tool sub" when the second word is a plain subcommand, else the tool.
+func subcommandOf(c Card) string {
+	f := strings.Fields(c.Command)
+	if len(f) > 1 && !strings.HasPrefix(f[1], "-") && !strings.HasPrefix(f[1], "<") && !isBadAnswerToken(f[1]) {
+		return f[0] + " " + f[1]
+	}
+	return toolOf(c)
+}
+
+// weakStats groups the review log by subcommand and by card.
+func weakStats(cards []Card, reviews []ReviewEntry) (groups, perCard []lapseStat) {
+	byID := map[string]Card{}
+	for _, c := range cards {
+		byID[c.ID] = c
+	}
+	g := map[string]*lapseStat{}
+	pc := map[string]*lapseStat{}
+	for _, r := range reviews {
+		c, ok := byID[r.CardID]
+		if !ok {
+			continue // deleted since
+		}
+		key := subcommandOf(c)
+		if g[key] == nil {
+			g[key] = &lapseStat{Key: key}
+		}
+		if pc[c.ID] == nil {
+			pc[c.ID] = &lapseStat{Key: c.Prompt, cardID: c.ID, wrong: map[string]int{}}
+		}
+		for _, s := range []*lapseStat{g[key], pc[c.ID]} {
+			s.Reviews++
+			if !r.Correct {
+				s.Lapses++
+			}
+		}
+		if !r.Correct && r.Answer != "" {
+			pc[c.ID].wrong[strings.ToLower(r.Answer)]++
+		}
+	}
+	for _, s := range g {
+		groups = append(groups, *s)
+	}
+	for _, s := range pc {
+		perCard = append(perCard, *s)
+	}
+	byRate := func(xs []lapseStat) {
+		sort.Slice(xs, func(i, j int) bool {
+			if xs[i].rate() != xs[j].rate() {
+				return xs[i].rate() > xs[j].rate()
+			}
+			if xs[i].Lapses != xs[j].Lapses {
+				return xs[i].Lapses > xs[j].Lapses
+			}
+			return xs[i].Key < xs[j].Key
+		})
+	}
+	byRate(groups)
+	byRate(perCard)
+	return groups, perCard
+}
+
+// weakSuggestions proposes fixes for a card that keeps lapsing.
+func weakSuggestions(c Card, s lapseStat) []string {
+	out := []string{}
+	var top string
+	for w, n := range s.wrong {
+		if n >= 2 && (top == "" || n > s.wrong[top] || n == s.wrong[top] && w < top) {
+			top = w
+		}
+	}
+	if top != "" {
+		out = append(out, fmt.Sprintf("you answered %q %d times: add a note on how it differs from %q", top, s.wrong[top], c.Answer))
+	}
+	if s.Lapses >= 3 && c.Kind() == "cloze" {
+		out = append(out, "regenerate it with a different cloze target (the blank may be the wrong thing to drill)")
+	}
+	if tool := toolOf(c); tool != "misc" && strings.HasPrefix(c.Answer, "-") {
+		page := tool
+		if sub := subcommandOf(c); tool == "git" && sub != tool {
+			page = "git-" + strings.Fields(sub)[1]
+		}
+		opt, _, _ := flagValue(c.Answer)
+		out = append(out, fmt.Sprintf("read the man section: man %s, then /%s", page, opt))
+	}
+	return out
+}
+
+func runWeak(args []string) error {
+	fs := flag.NewFlagSet("weak", flag.ExitOnError)
+	minReviews := fs.Int("min", 3, "ignore tools and cards with fewer reviews than this")
+	limit := fs.Int("limit", 10, "rows per section")
+	_ = fs.Parse(args)
+
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	reviews, err := LoadReviews()
+	if err != nil {
+		return err
+	}
+	if len(reviews) == 0 {
+		fmt.Println("No reviews logged yet; run memento review first.")
+		return nil
+	}
+	byID := map[string]Card{}
+	for _, c := range cards {
+		byID[c.ID] = c
+	}
+	groups, perCard := weakStats(cards, reviews)
+
+	fmt.Printf("Weakest tools/subcommands (lapse rate, ≥%d reviews):\n", *minReviews)
+	n := 0
+	for _, g := range groups {
+		if g.Reviews < *minReviews || g.Lapses == 0 || n == *limit {
+			continue
+		}
+		fmt.Printf("  %-24s %3.0f%%  (%d of %d failed)\n", g.Key, 100*g.rate(), g.Lapses, g.Reviews)
+		n++
+	}
+	if n == 0 {
+		fmt.Println("  nothing yet — no tool fails often enough to stand out")
+	}
+
+	fmt.Println("\nCards you keep failing:")
+	n = 0
+	for _, s := range perCard {
+		if s.Reviews < *minReviews || s.Lapses < 2 || n == *limit {
+			continue
+		}
+		c := byID[s.cardID]
+		fmt.Printf("  %s  %-40s answer %-18s failed %d of %d\n", shortID(c.ID), firstLine(c.Prompt), c.Answer, s.Lapses, s.Reviews)
+		for _, tip := range weakSuggestions(c, s) {
+			fmt.Println("      →", tip)
+		}
+		n++
+	}
+	if n == 0 {
+		fmt.Println("  none")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"memento/pkg/ingest"
+)
+
+// parseAge accepts "30d", "2w", "6mo", "1y", Go durations ("12h") or a date.
+// Callers prefix the error with their flag.
+func parseAge(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	bad := fmt.Errorf("bad span %q (try 30d, 2w, 6mo, 1y or 2024-01-31)", s)
+	for _, u := range []struct {
+		suffix  string
+		y, m, d int
+	}{{"mo", 0, 1, 0}, {"d", 0, 0, 1}, {"w", 0, 0, 7}, {"y", 1, 0, 0}} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, u.suffix)); err == nil && strings.HasSuffix(s, u.suffix) {
+			if n < 0 {
+				return time.Time{}, bad
+			}
+			return now.AddDate(-n*u.y, -n*u.m, -n*u.d), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, bad
+	}
+	return now.Add(-d), nil
+}
+
+// parseBetween reads "2024-01-01..2024-06-30" (end date inclusive; either side may be empty).
+func parseBetween(s string) (ingest.Window, error) {
+	from, to, ok := strings.Cut(s, "..")
+	if !ok {
+		return ingest.Window{}, fmt.Errorf("bad --between %q (want FROM..TO)", s)
+	}
+	var w ingest.Window
+	var err error
+	if from != "" {
+		if w.From, err = time.ParseInLocation("2006-01-02", from, time.Local); err != nil {
+			return w, fmt.Errorf("bad --between start: %w", err)
+		}
+	}
+	if to != "" {
+		if w.To, err = time.ParseInLocation("2006-01-02", to, time.Local); err != nil {
+			return w, fmt.Errorf("bad --between end: %w", err)
+		}
+		w.To = w.To.AddDate(0, 0, 1)
+	}
+	return w, nil
+}
+
+func ingestWindow(since, between string, now time.Time) (ingest.Window, error) {
+	switch {
+	case since != "" && between != "":
+		return ingest.Window{}, fmt.Errorf("use either --since or --between, not both")
+	case since != "":
+		from, err := parseAge(since, now)
+		if err != nil {
+			return ingest.Window{}, fmt.Errorf("--since: %w", err)
+		}
+		return ingest.Window{From: from}, nil
+	case between != "":
+		return parseBetween(between)
+	}
+	return ingest.Window{}, nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"memento/pkg/ingest"
+	"memento/pkg/storage"
+
+	"golang.org/x/term"
+)
+
+// firstRun is true before anything was ever configured or ingested.
+func firstRun() bool {
+	for _, f := range []func() (string, error){storage.CardsPath, configPath} {
+		p, err := f()
+		if err != nil {
+			return false
+		}
+		if _, err := os.Stat(p); !errors.Is(err, os.ErrNotExist) {
+			return false
+		}
+	}
+	return true
+}
+
+func interactive() bool { return term.IsTerminal(int(os.Stdin.Fd())) }
+
+// asker reads line answers from one buffered stdin.
+type asker struct {
+	r *bufio.Reader
+	w io.Writer
+}
+
+func (a asker) ask(prompt, def string) string {
+	if def != "" {
+		prompt += " [" + def + "]"
+	}
+	fmt.Fprint(a.w, prompt+" ")
+	line, _ := a.r.ReadString('\n')
+	if line = strings.TrimSpace(line); line != "" {
+		return line
+	}
+	return def
+}
+
+func (a asker) yes(prompt string, def bool) bool {
+	d := "y/N"
+	if def {
+		d = "Y/n"
+	}
+	fmt.Fprintf(a.w, "%s [%s] ", prompt, d)
+	line, _ := a.r.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// shellRC is where the wizard offers to add the `memento init` line.
+func shellRC(shell string) (path, line string) {
+	h, _ := os.UserHomeDir()
+	switch shell {
+	case "zsh":
+		return filepath.Join(h, ".zshrc"), `eval "$(memento init zsh)"`
+	case "bash":
+		return filepath.Join(h, ".bashrc"), `eval "$(memento init bash)"`
+	case "fish":
+		return filepath.Join(h, ".config", "fish", "config.fish"), "memento init fish | source"
+	}
+	return "", ""
+}
+
+func appendLine(path, line string) error {
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if strings.Contains(string(b), line) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "\n# memento: capture commands as you run them\n%s\n", line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func countLines(p string) int {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return 0
+	}
+	return strings.Count(string(b), "\n")
+}
+
+// runSetup is the first-run wizard (also `memento setup`): pick history files,
+// masking and secret handling, install shell hooks, then preview the first ingest.
+func runSetup(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: memento setup")
+	}
+	a := asker{r: bufio.NewReader(os.Stdin), w: os.Stdout}
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Welcome to memento. A few questions before reading your shell history.")
+
+	fmt.Println("\n1. History files")
+	files := []string{}
+	shells := []string{}
+	for _, p := range ingest.GuessHistoryFiles() {
+		sh := ingest.SniffShell(p)
+		if a.yes(fmt.Sprintf("   use %s (%s, %d lines)?", storage.TildePath(p), sh, countLines(p)), true) {
+			files = append(files, p)
+			shells = append(shells, sh)
+		}
+	}
+	for {
+		p := a.ask("   another history file (blank when done)?", "")
+		if p == "" {
+			break
+		}
+		if !fileExists(p) {
+			fmt.Println("   not found:", p)
+			continue
+		}
+		files = append(files, p)
+		shells = append(shells, ingest.SniffShell(p))
+	}
+	if len(files) == 0 {
+		fmt.Println("   no history files selected; cards will come from captures and `memento remember`.")
+	}
+	cfg.Ingest.HistoryFiles = files
+
+	fmt.Println("\n2. Masking")
+	fmt.Println("   paranoid: like standard, plus every number and dotted name (hosts, file names)")
+	fmt.Println("   standard: paths, long numbers, URLs, IPs, hashes become placeholders (<PATH>, <NUM>, …)")
+	fmt.Println("   domain:   like standard, but URLs keep scheme and host (https://api.github.com/<PATH>)")
+	fmt.Println("   minimal:  keep paths and numbers verbatim (private single-machine use)")
+	fmt.Println("   off:      keep commands as typed (secrets are still redacted)")
+	for {
+		m := a.ask("   masking level?", "standard")
+		if slices.Contains(maskingLevels, m) {
+			cfg.Ingest.Masking = m
+			break
+		}
+	}
+
+	fmt.Println("\n3. Secrets")
+	fmt.Println("   Tokens, keys, passwords and emails are always redacted before anything is stored.")
+	cfg.Scrub.DropSecrets = a.yes("   skip commands containing secrets entirely (instead of storing a redacted copy)?", cfg.Scrub.DropSecrets)
+	if p := a.ask("   extra gitleaks.toml rules (path, blank for none)?", cfg.Scrub.GitleaksConfig); p != "" {
+		if !fileExists(p) {
+			fmt.Println("   not found, skipping:", p)
+		} else {
+			cfg.Scrub.GitleaksConfig = p
+		}
+	}
+
+	if err := configure(cfg); err != nil {
+		return err
+	}
+	if err := SaveConfigFields(map[string]any{
+		"ingest.history_files":  cfg.Ingest.HistoryFiles,
+		"ingest.masking":        cfg.Ingest.Masking,
+		"scrub.drop_secrets":    cfg.Scrub.DropSecrets,
+		"scrub.gitleaks_config": cfg.Scrub.GitleaksConfig,
+	}); err != nil {
+		return err
+	}
+	cp, _ := configPath()
+	fmt.Println("   saved", storage.TildePath(cp))
+
+	fmt.Println("\n4. Shell hooks")
+	fmt.Println("   A hook records each command as you run it (with its directory), so new cards don't wait for history to be flushed.")
+	for _, sh := range unique(shells) {
+		rc, line := shellRC(sh)
+		if rc == "" || !a.yes(fmt.Sprintf("   add `%s` to %s?", line, storage.TildePath(rc)), false) {
+			continue
+		}
+		if err := appendLine(rc, line); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("\n5. First ingest")
+	cards, err := LoadCards()
+	if err != nil {
+		return err
+	}
+	st, err := loadStaging()
+	if err != nil {
+		return err
+	}
+	before := cloneDeck(cards)
+	created, staged, rejected := generateApproved(defaultSources(), ingest.Window{}, cards, st, time.Now())
+	st.stage(staged, rejected)
+	if err := saveStaging(st); err != nil {
+		return err
+	}
+	if len(staged) > 0 {
+		fmt.Printf("   %d borderline cards staged for `memento triage`.\n", len(staged))
+	}
+	if len(created) == 0 {
+		fmt.Println("   no tricky commands found yet; run `memento ingest` later.")
+		return nil
+	}
+	fmt.Printf("   %d cards would be created, e.g.:\n", len(created))
+	for i, c := range created {
+		if i == 8 {
+			break
+		}
+		fmt.Printf("     %-13s %s\n", c.Kind(), firstLine(c.Prompt))
+	}
+	if !a.yes("   save them?", true) {
+		fmt.Println("   nothing saved; adjust", storage.TildePath(cp), "and run `memento ingest`.")
+		return nil
+	}
+	if _, err := saveIngest(before, cards, created); err != nil {
+		return err
+	}
+	warnHook("post-ingest", created)
+	fmt.Printf("   saved %d cards. Start with `memento review`.\n", len(created))
+	return nil
+}
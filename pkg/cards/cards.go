@@ -0,0 +1,274 @@
+// Package cards is memento's data model: flashcards generated from shell
+// commands, and where those commands were seen.
+package cards
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Generator tags: a card carrying one of them is of that kind (see Kind);
+// anything else is a cloze card.
+const (
+	SequenceTag      = "sequence"
+	PipelineTag      = "pipeline"
+	ComprehensionTag = "comprehension"
+	DangerTag        = "danger"
+	ComboTag         = "combo"
+)
+
+// CardType is how a card is asked and graded, whichever generator made it.
+type CardType string
+
+const (
+	Cloze          CardType = "cloze"           // fill the blank(s) in the prompt
+	Reverse        CardType = "reverse"         // description in the prompt, type the command
+	FullRecall     CardType = "full-recall"     // type the whole answer from memory
+	Sequence       CardType = "sequence"        // the command that comes next in a workflow
+	Comprehension  CardType = "comprehension"   // describe what a command does, in words
+	MultipleChoice CardType = "multiple-choice" // pick the matching one of Choices
+	Order          CardType = "order"           // put shuffled pipeline stages in order
+)
+
+// Types lists every CardType.
+var Types = []CardType{Cloze, Reverse, FullRecall, Sequence, Comprehension, MultipleChoice, Order}
+
+// ParseType checks s against Types.
+func ParseType(s string) (CardType, error) {
+	for _, t := range Types {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown card type %q", s)
+}
+
+// Card represents a single flashcard generated from a shell command.
+type Card struct {
+	ID            string            `json:"id"`             // stable hash of normalized command
+	Type          CardType          `json:"type,omitempty"` // "" on cards saved before types: see TypeOf
+	Prompt        string            `json:"prompt"`
+	Answer        string            `json:"answer"`                   // often the hidden flag or full command
+	AltAnswers    []string          `json:"alt_answers,omitempty"`    // also accepted, e.g. the long form of a flag
+	AnswerPattern string            `json:"answer_pattern,omitempty"` // any matching answer is right: glob (-j*) or /regex/
+	Strictness    string            `json:"strictness,omitempty"`     // overrides review.strictness
+	Hint          string            `json:"hint"`
+	Command       string            `json:"command"`           // original (scrubbed)
+	Example       string            `json:"example,omitempty"` // latest concrete (scrubbed, unmasked) run of Command
+	Samples       map[string]string `json:"samples,omitempty"` // placeholder → a value from Example, e.g. <PATH> → ./build/output
+	Tags          []string          `json:"tags"`
+	Box           int               `json:"box"` // 1..srs.MaxBox() (Leitner)
+	NextDue       time.Time         `json:"next_due"`
+	LastReviewed  time.Time         `json:"last_reviewed"`
+	Streak        int               `json:"streak"`
+	TimesSeen     int               `json:"times_seen"`
+	SeenCount     int               `json:"seen_count"`
+	Notes         string            `json:"notes,omitempty"` // free-form, user-written
+	Origins       []Origin          `json:"origins,omitempty"`
+	Choices       []string          `json:"choices,omitempty"`   // multiple-choice options, if any
+	Suspended     bool              `json:"suspended,omitempty"` // never due until unsuspended
+	Pinned        bool              `json:"pinned,omitempty"`    // front of every session, due or not
+	Starred       bool              `json:"starred,omitempty"`   // favourite; filter with is:starred
+	Created       time.Time         `json:"created,omitempty"`
+	Stability     float64           `json:"stability,omitempty"`  // FSRS only: days until recall falls to 90%
+	Difficulty    float64           `json:"difficulty,omitempty"` // FSRS only: 1..10
+}
+
+// Origin records where a card's command was seen: one entry per host+history file.
+type Origin struct {
+	Host      string    `json:"host"`
+	Shell     string    `json:"shell"`
+	File      string    `json:"file"`
+	Cwd       string    `json:"cwd,omitempty"`
+	Project   string    `json:"project,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Due reports whether the card is up for review at now.
+func (c *Card) Due(now time.Time) bool {
+	return !c.Suspended && !now.Before(c.NextDue)
+}
+
+// Touch records a review at now.
+func (c *Card) Touch(now time.Time) { c.LastReviewed = now; c.TimesSeen++ }
+
+// Kind is the card flavour derived from its generator tag.
+func (c *Card) Kind() string {
+	for _, k := range []string{SequenceTag, PipelineTag, ComprehensionTag, DangerTag, ComboTag} {
+		if HasTag(*c, k) {
+			return k
+		}
+	}
+	return "cloze"
+}
+
+// TypeOf is c.Type, or for older cards the type their generator implies.
+func (c *Card) TypeOf() CardType {
+	if c.Type != "" {
+		return c.Type
+	}
+	switch c.Kind() {
+	case SequenceTag:
+		return Sequence
+	case PipelineTag:
+		return Order
+	case ComprehensionTag:
+		if len(c.Choices) > 0 {
+			return MultipleChoice
+		}
+		return Comprehension
+	case DangerTag:
+		return Comprehension
+	}
+	return Cloze
+}
+
+func (c *Card) String() string { return fmt.Sprintf("[%d] %s", c.Box, c.Prompt) }
+
+// ShortID is the 8-character form of id shown in listings.
+func ShortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// HasTag reports whether c carries tag.
+func HasTag(c Card, tag string) bool {
+	for _, t := range c.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Upsert adds incoming cards to existing, merging tags, origins and empty
+// text fields into cards already there.
+func Upsert(existing []Card, incoming []Card) []Card {
+	idx := map[string]int{}
+	for i, c := range existing {
+		idx[c.ID] = i
+	}
+	for _, c := range incoming {
+		if i, ok := idx[c.ID]; ok {
+			// merge lightweight updates (e.g., tags)
+			existing[i].Tags = Union(existing[i].Tags, c.Tags)
+			for _, o := range c.Origins {
+				existing[i].Origins = MergeOrigin(existing[i].Origins, o)
+			}
+			if existing[i].Prompt == "" {
+				existing[i].Prompt = c.Prompt
+			}
+			if existing[i].Answer == "" {
+				existing[i].Answer = c.Answer
+			}
+			if existing[i].Hint == "" {
+				existing[i].Hint = c.Hint
+			}
+		} else {
+			existing = append(existing, c)
+		}
+	}
+	return existing
+}
+
+// MergeOrigin adds o to list, widening the seen range of a matching entry.
+func MergeOrigin(list []Origin, o Origin) []Origin {
+	if o.Host == "" && o.File == "" {
+		return list
+	}
+	for i := range list {
+		if list[i].Host == o.Host && list[i].File == o.File && list[i].Project == o.Project {
+			if !o.FirstSeen.IsZero() && (list[i].FirstSeen.IsZero() || o.FirstSeen.Before(list[i].FirstSeen)) {
+				list[i].FirstSeen = o.FirstSeen
+			}
+			if o.LastSeen.After(list[i].LastSeen) {
+				list[i].LastSeen = o.LastSeen
+			}
+			list[i].Shell = o.Shell
+			if o.Cwd != "" {
+				list[i].Cwd, list[i].Project = o.Cwd, o.Project
+			}
+			return list
+		}
+	}
+	return append(list, o)
+}
+
+// FromHost reports whether the command was seen on host.
+func (c *Card) FromHost(host string) bool {
+	for _, o := range c.Origins {
+		if o.Host == host {
+			return true
+		}
+	}
+	return false
+}
+
+// Context describes where the command was run, e.g. "project infra (~/src/infra)".
+func (c *Card) Context() string {
+	for i := len(c.Origins) - 1; i >= 0; i-- {
+		o := c.Origins[i]
+		switch {
+		case o.Project != "":
+			return fmt.Sprintf("project %s (%s)", o.Project, o.Cwd)
+		case o.Cwd != "":
+			return "in " + o.Cwd
+		}
+	}
+	return ""
+}
+
+// Union is the set union of a and b, in no particular order.
+func Union(a, b []string) []string {
+	m := map[string]bool{}
+	for _, x := range a {
+		m[x] = true
+	}
+	for _, x := range b {
+		m[x] = true
+	}
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+// FirstSeen is the earliest origin timestamp, else now (stamps Created on save).
+func (c *Card) FirstSeen(now time.Time) time.Time {
+	t := now
+	for _, o := range c.Origins {
+		if !o.FirstSeen.IsZero() && o.FirstSeen.Before(t) {
+			t = o.FirstSeen
+		}
+	}
+	return t
+}
+
+// Find resolves a full ID or unique ID prefix to an index.
+func Find(cards []Card, prefix string) (int, error) {
+	if prefix == "" {
+		return -1, errors.New("empty card id")
+	}
+	found := -1
+	for i, c := range cards {
+		if c.ID == prefix {
+			return i, nil
+		}
+		if strings.HasPrefix(c.ID, prefix) {
+			if found >= 0 {
+				return -1, fmt.Errorf("card id %q is ambiguous", prefix)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("no card with id %q", prefix)
+	}
+	return found, nil
+}
@@ -0,0 +1,50 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CaptureEntry is one command recorded by the shell hook, appended to capture.jsonl.
+// Commands are scrubbed before they touch disk.
+type CaptureEntry struct {
+	When    time.Time `json:"when"`
+	Shell   string    `json:"shell,omitempty"`
+	Cwd     string    `json:"cwd,omitempty"`
+	Project string    `json:"project,omitempty"` // git repo name, if any
+	Command string    `json:"command"`
+}
+
+// DetectProject walks up from dir looking for a git repository root.
+func DetectProject(dir string) string {
+	for d := dir; d != "" && d != "/" && d != "."; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return filepath.Base(d)
+		}
+	}
+	return ""
+}
+
+// ReadCaptures loads a capture log; a missing file is empty.
+func ReadCaptures(p string) ([]CaptureEntry, error) {
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	out := []CaptureEntry{}
+	s := newScanner(f)
+	for s.Scan() {
+		var e CaptureEntry
+		if json.Unmarshal(s.Bytes(), &e) == nil && e.Command != "" {
+			out = append(out, e)
+		}
+	}
+	return out, s.Err()
+}
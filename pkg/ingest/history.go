@@ -0,0 +1,281 @@
+// Package ingest reads shell history: bash, zsh and fish history files,
+// user-defined line formats, and the capture log written by memento's shell hook.
+package ingest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// Logger receives unreadable history files; it discards until set.
+var Logger = slog.New(slog.DiscardHandler)
+
+// maxLine is the longest history line read (a pasted heredoc, a base64 blob);
+// a longer one stops that file with bufio.ErrTooLong.
+const maxLine = 1024 * 1024
+
+func newScanner(r io.Reader) *bufio.Scanner {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), maxLine)
+	return s
+}
+
+// Source is one history file and the format to parse it with:
+// zsh, bash, fish, or capture (memento's own hook log).
+type Source struct {
+	Path  string `json:"path"`
+	Shell string `json:"shell"`
+}
+
+// Walk feeds every raw command of every source to visit.
+func Walk(srcs []Source, visit func(raw string, when time.Time, origin cards.Origin)) {
+	host, _ := os.Hostname()
+	for _, src := range srcs {
+		if src.Shell == "capture" {
+			// commands recorded live by the shell hook (memento init)
+			entries, err := ReadCaptures(src.Path)
+			if err != nil {
+				Logger.Warn("capture log: stopped reading", "file", src.Path, "err", err)
+			}
+			for _, e := range entries {
+				visit(e.Command, e.When, cards.Origin{Host: host, Shell: e.Shell, File: src.Path, Cwd: e.Cwd, Project: e.Project})
+			}
+			continue
+		}
+		f, err := os.Open(src.Path)
+		if err != nil {
+			continue
+		}
+		origin := cards.Origin{Host: host, Shell: src.Shell, File: src.Path}
+		emit := func(raw string, when time.Time) { visit(raw, when, origin) }
+		scan := ScanBash
+		switch src.Shell {
+		case "zsh":
+			scan = ScanZsh
+		case "fish":
+			scan = ScanFish
+		}
+		if err := scan(f, emit); err != nil {
+			Logger.Warn("history: stopped reading", "file", src.Path, "err", err)
+		}
+		_ = f.Close()
+	}
+}
+
+// ScanBash reads plain or timestamped bash history.
+func ScanBash(r io.Reader, emit func(string, time.Time)) error {
+	var stamp time.Time // from a preceding bash "#<epoch>" line (HISTTIMEFORMAT)
+	s := newScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		if m := bashStamp.FindStringSubmatch(line); m != nil {
+			sec, _ := strconv.ParseInt(m[1], 10, 64)
+			stamp = time.Unix(sec, 0)
+			continue
+		}
+		raw, when := NormalizeLine(line)
+		if when.IsZero() {
+			when = stamp
+		}
+		stamp = time.Time{}
+		emit(raw, when)
+	}
+	return s.Err()
+}
+
+// ScanZsh joins multi-line entries (continued with a trailing backslash).
+func ScanZsh(r io.Reader, emit func(string, time.Time)) error {
+	s := newScanner(r)
+	entry := ""
+	for s.Scan() {
+		line := s.Text()
+		if strings.HasSuffix(line, "\\") {
+			entry += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		entry += line
+		if e := strings.TrimSpace(entry); e != "" {
+			if m := zshExt.FindStringSubmatch(e); m != nil {
+				sec, _ := strconv.ParseInt(m[1], 10, 64)
+				emit(strings.TrimSpace(strings.TrimPrefix(e, m[0])), time.Unix(sec, 0))
+			} else {
+				emit(e, time.Time{})
+			}
+		}
+		entry = ""
+	}
+	return s.Err()
+}
+
+// ScanFish reads fish_history's YAML-ish "- cmd: …" / "  when: …" records.
+func ScanFish(r io.Reader, emit func(string, time.Time)) error {
+	s := newScanner(r)
+	cmd := ""
+	flush := func(when time.Time) {
+		if cmd != "" {
+			emit(strings.ReplaceAll(cmd, "\\n", " "), when)
+		}
+		cmd = ""
+	}
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case strings.HasPrefix(line, "- cmd: "):
+			flush(time.Time{})
+			cmd = strings.TrimPrefix(line, "- cmd: ")
+		case strings.HasPrefix(line, "  when: "):
+			sec, _ := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "  when: ")), 10, 64)
+			flush(time.Unix(sec, 0))
+		}
+	}
+	flush(time.Time{})
+	return s.Err()
+}
+
+// SniffShell guesses the format from the first lines, falling back to the file name.
+func SniffShell(p string) string {
+	if f, err := os.Open(p); err == nil {
+		defer f.Close()
+		s := newScanner(f)
+		for i := 0; i < 50 && s.Scan(); i++ {
+			line := s.Text()
+			if strings.HasPrefix(line, "- cmd: ") {
+				return "fish"
+			}
+			if m := zshExt.FindStringSubmatch(line); m != nil && len(m[1]) >= 9 {
+				return "zsh"
+			}
+		}
+	}
+	return ShellForFile(p)
+}
+
+// GuessHistoryFiles lists the usual history files that exist for this user.
+func GuessHistoryFiles() []string {
+	h, _ := os.UserHomeDir()
+	candidates := []string{
+		filepath.Join(h, ".zsh_history"),
+		filepath.Join(h, ".bash_history"),
+		filepath.Join(h, ".bash_eternal_history"),
+		filepath.Join(h, ".local", "share", "fish", "fish_history"),
+	}
+	if hf := os.Getenv("HISTFILE"); hf != "" {
+		candidates = append(candidates, hf)
+	}
+	out := []string{}
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil && !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ShellForFile guesses the format from the file name alone.
+func ShellForFile(p string) string {
+	switch base := filepath.Base(p); {
+	case strings.Contains(base, "zsh"):
+		return "zsh"
+	case strings.Contains(base, "fish"):
+		return "fish"
+	}
+	return "bash"
+}
+
+var (
+	zshExt    = regexp.MustCompile(`^: (\d+):(\d+);`)
+	bashStamp = regexp.MustCompile(`^#(\d{9,11})$`)
+	// optional "pid user histnum" prefix, then the HISTTIMEFORMAT-style date
+	eternalDate = regexp.MustCompile(`^(?:\d+\s+\S+\s+\d+\s+)?\[?(\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}(?::\d{2})?)\]?\s+(.+)$`)
+)
+
+// NormalizeLine splits a single history line into the command and its timestamp, if any.
+func NormalizeLine(line string) (cmd string, when time.Time) {
+	for _, lf := range LineFormats {
+		if cmd, when, ok := lf.parse(line); ok {
+			return cmd, when
+		}
+	}
+	if m := zshExt.FindStringSubmatch(line); len(m) == 3 {
+		// Zsh extended history (": epoch:duration;cmd"); some PROMPT_COMMAND
+		// loggers write ": pid:epoch;cmd" instead, so take whichever looks like an epoch.
+		epoch := m[1]
+		if len(epoch) < 9 && len(m[2]) >= 9 {
+			epoch = m[2]
+		}
+		// strip prefix
+		cmd = strings.TrimSpace(strings.TrimPrefix(line, m[0]))
+		sec, _ := time.ParseDuration(epoch + "s")
+		when = time.Unix(0, 0).Add(sec)
+		return cmd, when
+	}
+	if m := eternalDate.FindStringSubmatch(line); m != nil {
+		// "eternal history": [pid user histnum] YYYY-MM-DD HH:MM[:SS] cmd
+		for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"} {
+			if t, err := time.ParseInLocation(layout, m[1], time.Local); err == nil {
+				return strings.TrimSpace(m[2]), t
+			}
+		}
+	}
+	// Bash: just the command; no timestamp
+	return line, time.Time{}
+}
+
+// LineFormat is a user-supplied history format: a regex with named groups
+// "ts" and "cmd", and a Go time layout for ts ("epoch" for Unix seconds).
+type LineFormat struct {
+	re     *regexp.Regexp
+	layout string
+}
+
+// LineFormats are tried before the built-in formats by NormalizeLine.
+var LineFormats []LineFormat
+
+// CompileLineFormat builds a LineFormat; an empty layout means "epoch".
+func CompileLineFormat(regex, layout string) (LineFormat, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return LineFormat{}, fmt.Errorf("line format %q: %w", regex, err)
+	}
+	if re.SubexpIndex("cmd") < 0 {
+		return LineFormat{}, fmt.Errorf("line format %q: needs a (?P<cmd>...) group", regex)
+	}
+	if layout == "" {
+		layout = "epoch"
+	}
+	return LineFormat{re: re, layout: layout}, nil
+}
+
+func (lf LineFormat) parse(line string) (string, time.Time, bool) {
+	m := lf.re.FindStringSubmatch(line)
+	if m == nil {
+		return "", time.Time{}, false
+	}
+	cmd := strings.TrimSpace(m[lf.re.SubexpIndex("cmd")])
+	var when time.Time
+	if i := lf.re.SubexpIndex("ts"); i >= 0 && m[i] != "" {
+		if lf.layout == "epoch" {
+			if sec, err := strconv.ParseInt(m[i], 10, 64); err == nil {
+				when = time.Unix(sec, 0)
+			}
+		} else if t, err := time.ParseInLocation(lf.layout, m[i], time.Local); err == nil {
+			when = t
+		}
+	}
+	return cmd, when, true
+}
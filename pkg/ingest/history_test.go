@@ -0,0 +1,192 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type entry struct {
+	cmd  string
+	when time.Time
+}
+
+func collect(t *testing.T, scan func(r *strings.Reader, emit func(string, time.Time)) error, in string) []entry {
+	t.Helper()
+	var out []entry
+	if err := scan(strings.NewReader(in), func(cmd string, when time.Time) { out = append(out, entry{cmd, when}) }); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	return out
+}
+
+func sameEntries(a, b []entry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].cmd != b[i].cmd || !a[i].when.Equal(b[i].when) {
+			return false
+		}
+	}
+	return true
+}
+
+func local(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNormalizeLine(t *testing.T) {
+	tests := []struct {
+		name, line, cmd string
+		when            time.Time
+	}{
+		{"plain bash", "ls -la", "ls -la", time.Time{}},
+		{"zsh extended", ": 1700000000:0;git status", "git status", time.Unix(1700000000, 0)},
+		{"zsh extended, long duration", ": 1700000000:12;make", "make", time.Unix(1700000000, 0)},
+		{"pid:epoch logger", ": 4242:1700000000;kubectl get pods", "kubectl get pods", time.Unix(1700000000, 0)},
+		{"eternal history", "2024-01-31 10:20:30 git log", "git log", local("2024-01-31 10:20:30")},
+		{"eternal, T and no seconds", "2024-01-31T10:20 tar xzf a.tgz", "tar xzf a.tgz", local("2024-01-31 10:20:00")},
+		{"eternal, bracketed", "[2024-01-31 10:20:30] docker ps", "docker ps", local("2024-01-31 10:20:30")},
+		{"eternal, pid user histnum", "1234 alice 56 2024-01-31 10:20:30 ssh host", "ssh host", local("2024-01-31 10:20:30")},
+		{"date-like argument", "echo 2024-01-31", "echo 2024-01-31", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, when := NormalizeLine(tt.line)
+			if cmd != tt.cmd || !when.Equal(tt.when) {
+				t.Errorf("NormalizeLine(%q) = %q, %v; want %q, %v", tt.line, cmd, when, tt.cmd, tt.when)
+			}
+		})
+	}
+}
+
+func TestLineFormats(t *testing.T) {
+	epoch, err := CompileLineFormat(`^(?P<ts>\d+)\|(?P<cmd>.*)$`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout, err := CompileLineFormat(`^(?P<ts>\S+ \S+) > (?P<cmd>.*)$`, "02.01.2006 15:04:05")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(saved []LineFormat) { LineFormats = saved }(LineFormats)
+	LineFormats = []LineFormat{epoch, layout}
+
+	tests := []struct {
+		line, cmd string
+		when      time.Time
+	}{
+		{"1700000000|git fetch --all", "git fetch --all", time.Unix(1700000000, 0)},
+		{"31.01.2024 10:20:30 > rsync -av a b", "rsync -av a b", local("2024-01-31 10:20:30")},
+		{"notanumber|ls", "notanumber|ls", time.Time{}}, // falls through to plain bash
+		{": 1700000000:0;git status", "git status", time.Unix(1700000000, 0)},
+	}
+	for _, tt := range tests {
+		cmd, when := NormalizeLine(tt.line)
+		if cmd != tt.cmd || !when.Equal(tt.when) {
+			t.Errorf("NormalizeLine(%q) = %q, %v; want %q, %v", tt.line, cmd, when, tt.cmd, tt.when)
+		}
+	}
+}
+
+func TestCompileLineFormatErrors(t *testing.T) {
+	for _, re := range []string{`(`, `^(?P<ts>\d+) (.*)$`} {
+		if _, err := CompileLineFormat(re, ""); err == nil {
+			t.Errorf("CompileLineFormat(%q): want an error", re)
+		}
+	}
+}
+
+func TestScanBash(t *testing.T) {
+	scan := func(r *strings.Reader, emit func(string, time.Time)) error { return ScanBash(r, emit) }
+	long := strings.Repeat("A", 300*1024)
+	tests := []struct {
+		name, in string
+		want     []entry
+	}{
+		{"plain", "ls\n\n  git status  \n", []entry{{"ls", time.Time{}}, {"git status", time.Time{}}}},
+		{"epoch pairs with the next line only", "#1700000000\ngit push\nls\n", []entry{{"git push", time.Unix(1700000000, 0)}, {"ls", time.Time{}}}},
+		{"last epoch wins", "#1700000000\n#1700000060\nmake\n", []entry{{"make", time.Unix(1700000060, 0)}}},
+		{"short hash is a comment, not a stamp", "#123\nls\n", []entry{{"#123", time.Time{}}, {"ls", time.Time{}}}},
+		{"line over 64 KB", "echo " + long + "\necho after\n", []entry{{"echo " + long, time.Time{}}, {"echo after", time.Time{}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collect(t, scan, tt.in); !sameEntries(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanTooLong(t *testing.T) {
+	in := strings.Repeat("x", maxLine+1) + "\nls\n"
+	for name, scan := range map[string]func(r *strings.Reader, emit func(string, time.Time)) error{
+		"bash": func(r *strings.Reader, emit func(string, time.Time)) error { return ScanBash(r, emit) },
+		"zsh":  func(r *strings.Reader, emit func(string, time.Time)) error { return ScanZsh(r, emit) },
+		"fish": func(r *strings.Reader, emit func(string, time.Time)) error { return ScanFish(r, emit) },
+	} {
+		if err := scan(strings.NewReader(in), func(string, time.Time) {}); err == nil {
+			t.Errorf("%s: want an error for a line over %d bytes", name, maxLine)
+		}
+	}
+}
+
+func TestScanZsh(t *testing.T) {
+	scan := func(r *strings.Reader, emit func(string, time.Time)) error { return ScanZsh(r, emit) }
+	in := ": 1700000000:0;git commit\\\n-m msg\n: 1700000060:3;ls\nplain\n"
+	want := []entry{
+		{"git commit -m msg", time.Unix(1700000000, 0)},
+		{"ls", time.Unix(1700000060, 0)},
+		{"plain", time.Time{}},
+	}
+	if got := collect(t, scan, in); !sameEntries(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestScanFish(t *testing.T) {
+	scan := func(r *strings.Reader, emit func(string, time.Time)) error { return ScanFish(r, emit) }
+	in := "- cmd: git status\n  when: 1700000000\n- cmd: echo a\\nb\n- cmd: ls\n  when: 1700000060\n  paths:\n    - foo\n"
+	want := []entry{
+		{"git status", time.Unix(1700000000, 0)},
+		{"echo a b", time.Time{}},
+		{"ls", time.Unix(1700000060, 0)},
+	}
+	if got := collect(t, scan, in); !sameEntries(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSniffShell(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, content, want string
+	}{
+		{"history", "- cmd: ls\n  when: 1700000000\n", "fish"},
+		{"history2", ": 1700000000:0;ls\n", "zsh"},
+		{"history3", "ls\ngit status\n", "bash"},
+		{".zsh_history", "ls\n", "zsh"},                                         // no extended lines: by name
+		{"pid_log", ": 4242:17;ls\n", "bash"},                                   // too short for an epoch
+		{"long_first", strings.Repeat("y", 100*1024) + "\n- cmd: ls\n", "fish"}, // a long line doesn't stop the sniff
+	}
+	for _, tt := range tests {
+		p := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(p, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := SniffShell(p); got != tt.want {
+			t.Errorf("SniffShell(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := SniffShell(filepath.Join(dir, "missing_fish_history")); got != "fish" {
+		t.Errorf("missing file: got %q, want the name-based fish", got)
+	}
+}
@@ -0,0 +1,22 @@
+package ingest
+
+import "time"
+
+// Window limits ingest to commands run within [From, To). Zero bounds are open.
+type Window struct {
+	From, To time.Time
+}
+
+// Open reports whether neither bound is set.
+func (w Window) Open() bool { return w.From.IsZero() && w.To.IsZero() }
+
+// Contains rejects untimestamped commands whenever the window is bounded.
+func (w Window) Contains(t time.Time) bool {
+	if w.Open() {
+		return true
+	}
+	if t.IsZero() {
+		return false
+	}
+	return (w.From.IsZero() || !t.Before(w.From)) && (w.To.IsZero() || t.Before(w.To))
+}
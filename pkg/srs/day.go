@@ -0,0 +1,14 @@
+package srs
+
+import "time"
+
+// DayStartHour is display.day_start_hour: a review day runs from this local
+// hour to the same hour the next day, so a 1 AM session still counts as the
+// evening before.
+var DayStartHour int
+
+// DayStart is the start of the review day containing t.
+func DayStart(t time.Time) time.Time {
+	y, m, d := t.Local().Add(-time.Duration(DayStartHour) * time.Hour).Date()
+	return time.Date(y, m, d, DayStartHour, 0, 0, 0, time.Local)
+}
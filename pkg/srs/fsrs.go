@@ -0,0 +1,125 @@
+package srs
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// FSRS (Free Spaced Repetition Scheduler, v4.5 default weights) models each
+// card by stability (days until recall drops to 90%) and difficulty (1..10).
+// It suits cards with no history behind them, like discover decks, better
+// than fixed Leitner boxes.
+
+// Rating grades an answer for FSRS.
+type Rating int
+
+const (
+	RateAgain Rating = iota + 1
+	RateHard
+	RateGood
+	RateEasy
+)
+
+var fsrsW = [17]float64{0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474, 0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755}
+
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81
+)
+
+// FSRSRetention is review.fsrs_retention: the recall probability to schedule at.
+var FSRSRetention = 0.9
+
+func fsrsRecall(elapsedDays, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsedDays/stability, fsrsDecay)
+}
+
+func fsrsInitDifficulty(r Rating) float64 {
+	return clamp(fsrsW[4]-float64(r-3)*fsrsW[5], 1, 10)
+}
+
+func clamp(x, lo, hi float64) float64 { return math.Min(math.Max(x, lo), hi) }
+
+// FSRSGrade updates stability and difficulty and schedules the next review.
+// Box follows the interval, so box-based views keep making sense.
+func FSRSGrade(c *cards.Card, r Rating, now time.Time) {
+	elapsed := now.Sub(c.LastReviewed).Hours() / 24
+	first := c.Stability == 0 || c.LastReviewed.IsZero()
+	c.Touch(now)
+	if first {
+		c.Stability = fsrsW[r-1]
+		c.Difficulty = fsrsInitDifficulty(r)
+	} else {
+		R := fsrsRecall(math.Max(elapsed, 0), c.Stability)
+		if r == RateAgain {
+			c.Stability = fsrsW[11] * math.Pow(c.Difficulty, -fsrsW[12]) * (math.Pow(c.Stability+1, fsrsW[13]) - 1) * math.Exp(fsrsW[14]*(1-R))
+		} else {
+			bonus := 1.0
+			switch r {
+			case RateHard:
+				bonus = fsrsW[15]
+			case RateEasy:
+				bonus = fsrsW[16]
+			}
+			c.Stability *= 1 + math.Exp(fsrsW[8])*(11-c.Difficulty)*math.Pow(c.Stability, -fsrsW[9])*(math.Exp(fsrsW[10]*(1-R))-1)*bonus
+		}
+		d := c.Difficulty - fsrsW[6]*float64(r-3)
+		c.Difficulty = clamp(fsrsW[7]*fsrsInitDifficulty(RateGood)+(1-fsrsW[7])*d, 1, 10)
+	}
+	if r == RateAgain {
+		c.Streak = 0
+		c.NextDue = now
+		c.Box = 1
+		return
+	}
+	c.Streak++
+	days := math.Max(1, math.Round(c.Stability/fsrsFactor*(math.Pow(FSRSRetention, 1/fsrsDecay)-1)))
+	c.NextDue = now.Add(fuzzInterval(time.Duration(days * 24 * float64(time.Hour))))
+	c.Box = BoxForInterval(int(days))
+}
+
+// Schedulers is review.schedulers (tag → scheduler) and DefaultScheduler
+// review.scheduler.
+var (
+	Schedulers       map[string]string
+	DefaultScheduler = "leitner"
+)
+
+// CheckScheduler accepts the scheduler names: leitner and fsrs.
+func CheckScheduler(name string) error {
+	switch name {
+	case "leitner", "fsrs":
+		return nil
+	}
+	return fmt.Errorf("unknown scheduler %q (want leitner or fsrs)", name)
+}
+
+// SchedulerFor picks the scheduler of the card's tags that have one. Tag
+// order isn't stable (ingest merges tag sets), so if several do, the
+// alphabetically first tag wins.
+func SchedulerFor(c cards.Card) string {
+	best := ""
+	for _, t := range c.Tags {
+		if _, ok := Schedulers[t]; ok && (best == "" || t < best) {
+			best = t
+		}
+	}
+	if best == "" {
+		return DefaultScheduler
+	}
+	return Schedulers[best]
+}
+
+// BoxForInterval maps an interval in days onto the nearest Leitner box.
+func BoxForInterval(days int) int {
+	box := 1
+	for b := 2; b <= MaxBox(); b++ {
+		if time.Duration(days)*24*time.Hour >= Boxes[b] {
+			box = b
+		}
+	}
+	return box
+}
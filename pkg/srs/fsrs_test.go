@@ -0,0 +1,130 @@
+package srs
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+var t0 = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+func days(d time.Duration) float64 { return d.Hours() / 24 }
+
+func TestFSRSRecall(t *testing.T) {
+	for _, s := range []float64{0.5, 1, 3.7, 40, 365} {
+		if r := fsrsRecall(0, s); r != 1 {
+			t.Errorf("recall(0, %v) = %v, want 1", s, r)
+		}
+		if r := fsrsRecall(s, s); math.Abs(r-0.9) > 1e-9 {
+			t.Errorf("recall(S, S) = %v for S=%v, want 0.9", r, s)
+		}
+		if fsrsRecall(2*s, s) >= fsrsRecall(s, s) {
+			t.Errorf("recall doesn't decay for S=%v", s)
+		}
+	}
+}
+
+func TestFSRSFirstGrade(t *testing.T) {
+	tests := []struct {
+		r          Rating
+		stability  float64
+		difficulty float64
+		days       float64
+		box        int
+	}{
+		{RateAgain, fsrsW[0], clamp(fsrsW[4]+2*fsrsW[5], 1, 10), 0, 1},
+		{RateHard, fsrsW[1], fsrsW[4] + fsrsW[5], 1, 2},
+		{RateGood, fsrsW[2], fsrsW[4], 4, 3},
+		{RateEasy, fsrsW[3], fsrsW[4] - fsrsW[5], 14, 4},
+	}
+	for _, tt := range tests {
+		c := cards.Card{Box: 1}
+		FSRSGrade(&c, tt.r, t0)
+		if c.Stability != tt.stability || math.Abs(c.Difficulty-tt.difficulty) > 1e-9 {
+			t.Errorf("rating %d: S=%v D=%v, want S=%v D=%v", tt.r, c.Stability, c.Difficulty, tt.stability, tt.difficulty)
+		}
+		if got := days(c.NextDue.Sub(t0)); got != tt.days {
+			t.Errorf("rating %d: due in %v days, want %v", tt.r, got, tt.days)
+		}
+		if c.Box != tt.box || !c.LastReviewed.Equal(t0) || c.TimesSeen != 1 {
+			t.Errorf("rating %d: box %d, last reviewed %v, seen %d", tt.r, c.Box, c.LastReviewed, c.TimesSeen)
+		}
+	}
+}
+
+func TestFSRSSecondGrade(t *testing.T) {
+	after := map[Rating]cards.Card{}
+	for _, r := range []Rating{RateAgain, RateHard, RateGood, RateEasy} {
+		c := cards.Card{}
+		FSRSGrade(&c, RateGood, t0)
+		FSRSGrade(&c, r, c.NextDue)
+		after[r] = c
+	}
+	s0 := fsrsW[2]
+	if after[RateAgain].Stability >= s0 {
+		t.Errorf("again: stability %v should drop below %v", after[RateAgain].Stability, s0)
+	}
+	if !(s0 < after[RateHard].Stability && after[RateHard].Stability < after[RateGood].Stability && after[RateGood].Stability < after[RateEasy].Stability) {
+		t.Errorf("want %v < hard < good < easy, got %v %v %v", s0, after[RateHard].Stability, after[RateGood].Stability, after[RateEasy].Stability)
+	}
+	if !(after[RateAgain].Difficulty > after[RateGood].Difficulty && after[RateGood].Difficulty > after[RateEasy].Difficulty) {
+		t.Errorf("want difficulty again > good > easy, got %v %v %v", after[RateAgain].Difficulty, after[RateGood].Difficulty, after[RateEasy].Difficulty)
+	}
+	if c := after[RateAgain]; c.Streak != 0 || c.Box != 1 {
+		t.Errorf("again: streak %d box %d, want 0 and 1", c.Streak, c.Box)
+	}
+	if c := after[RateGood]; c.Streak != 2 || c.NextDue.Sub(c.LastReviewed) != time.Duration(math.Round(c.Stability))*24*time.Hour {
+		t.Errorf("good: streak %d, interval %v for S=%v", c.Streak, c.NextDue.Sub(c.LastReviewed), c.Stability)
+	}
+}
+
+func TestFSRSDifficultyBounds(t *testing.T) {
+	for _, r := range []Rating{RateAgain, RateEasy} {
+		c := cards.Card{}
+		now := t0
+		for range 50 {
+			FSRSGrade(&c, r, now)
+			now = now.Add(24 * time.Hour)
+		}
+		if c.Difficulty < 1 || c.Difficulty > 10 || c.Stability <= 0 || math.IsNaN(c.Stability) {
+			t.Errorf("rating %d x50: D=%v S=%v", r, c.Difficulty, c.Stability)
+		}
+	}
+}
+
+func TestFSRSRetention(t *testing.T) {
+	defer func(r float64) { FSRSRetention = r }(FSRSRetention)
+	interval := func(retention float64) time.Duration {
+		FSRSRetention = retention
+		c := cards.Card{}
+		FSRSGrade(&c, RateEasy, t0)
+		return c.NextDue.Sub(t0)
+	}
+	if lo, hi := interval(0.8), interval(0.95); lo <= hi {
+		t.Errorf("retention 0.8 gives %v, 0.95 gives %v: want the lower target to wait longer", lo, hi)
+	}
+}
+
+func TestSchedulerFor(t *testing.T) {
+	defer func(m map[string]string, d string) { Schedulers, DefaultScheduler = m, d }(Schedulers, DefaultScheduler)
+	Schedulers, DefaultScheduler = map[string]string{"discover": "fsrs", "git": "leitner", "kb": "fsrs"}, "leitner"
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{nil, "leitner"},
+		{[]string{"docker"}, "leitner"},
+		{[]string{"docker", "kb"}, "fsrs"},
+		{[]string{"git", "discover"}, "fsrs"}, // discover < git
+		{[]string{"discover", "git"}, "fsrs"},
+		{[]string{"kb", "git"}, "leitner"}, // git < kb
+		{[]string{"git", "kb"}, "leitner"},
+	}
+	for _, tt := range tests {
+		if got := SchedulerFor(cards.Card{Tags: tt.tags}); got != tt.want {
+			t.Errorf("SchedulerFor(%v) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
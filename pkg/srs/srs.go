@@ -0,0 +1,337 @@
+// Package srs schedules card reviews: a Leitner ladder by default, FSRS per
+// tag or deck-wide, and the queue helpers a review session is built from.
+// The package variables are the review.* settings; the memento command sets
+// them from its config, library users set them directly.
+package srs
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// Boxes is the Leitner ladder, box → interval (review.boxes); see SetBoxes.
+var Boxes = map[int]time.Duration{
+	1: 0,
+	2: 24 * time.Hour,
+	3: 3 * 24 * time.Hour,
+	4: 7 * 24 * time.Hour,
+	5: 21 * 24 * time.Hour,
+}
+
+// MaxBox is the top box of the ladder.
+func MaxBox() int { return len(Boxes) }
+
+// SetBoxes installs a custom ladder: intervals[i] is box i+1's.
+func SetBoxes(intervals []time.Duration) error {
+	if len(intervals) < 2 {
+		return fmt.Errorf("want at least 2 boxes, got %d", len(intervals))
+	}
+	m := map[int]time.Duration{}
+	for i, d := range intervals {
+		if i > 0 && d < intervals[i-1] {
+			return fmt.Errorf("box %d (%s) is shorter than box %d", i+1, d, i)
+		}
+		m[i+1] = d
+	}
+	Boxes = m
+	return nil
+}
+
+// OverdueAware is review.overdue_aware: grade against how late a review was.
+var OverdueAware bool
+
+// Grade moves card up a box when correct, down one when not, and reschedules it.
+func Grade(card *cards.Card, correct bool, now time.Time) {
+	late, elapsed := overdueRatio(*card, now), now.Sub(card.LastReviewed)
+	card.Touch(now)
+	if correct {
+		card.Box = min(card.Box, MaxBox()) // ladder may have shrunk
+		if card.Box < MaxBox() {
+			card.Box++
+		}
+		// remembered for at least the next box's interval already: skip it
+		if late > 1 && card.Box < MaxBox() && elapsed >= IntervalFor(*card) {
+			card.Box++
+		}
+		card.Streak++
+	} else {
+		// forgetting after twice the interval is expected, not a lapse of the
+		// box: keep it and just relearn soon
+		if card.Box > 1 && late < 2 {
+			card.Box--
+		}
+		if card.Streak > 0 {
+			card.Streak = 0
+		}
+	}
+	reschedule(card, now)
+	if !correct && late >= 2 {
+		card.NextDue = now
+	}
+}
+
+// overdueRatio is time since the last review over the interval that was
+// scheduled then (0 when unknown or under a day, or when not overdue-aware).
+func overdueRatio(c cards.Card, now time.Time) float64 {
+	scheduled := c.NextDue.Sub(c.LastReviewed)
+	if !OverdueAware || c.LastReviewed.IsZero() || scheduled < 24*time.Hour {
+		return 0
+	}
+	return float64(now.Sub(c.LastReviewed)) / float64(scheduled)
+}
+
+// GradeHard is a correct answer that needed help (e.g. a revealed hint):
+// the card stays in its box and keeps its streak.
+func GradeHard(card *cards.Card, now time.Time) {
+	card.Touch(now)
+	reschedule(card, now)
+}
+
+// SpeedSettings stretches or shrinks the interval of a correct answer by how
+// long it took: under Fast earns FastBonus, over Slow only SlowFactor.
+type SpeedSettings struct {
+	Enabled    bool
+	Fast, Slow time.Duration
+	FastBonus  float64
+	SlowFactor float64
+}
+
+// Speed is review.speed.
+var Speed SpeedSettings
+
+// typingPerChar is subtracted per answer character, so whole-command answers
+// aren't judged slow just for being long to type.
+const typingPerChar = 150 * time.Millisecond
+
+// SpeedFactor scales the interval earned by a correct answer that took took.
+func SpeedFactor(took time.Duration, answer string) float64 {
+	if !Speed.Enabled || took <= 0 {
+		return 1
+	}
+	took -= time.Duration(len([]rune(answer))) * typingPerChar
+	switch {
+	case took <= Speed.Fast:
+		return Speed.FastBonus
+	case took >= Speed.Slow:
+		return Speed.SlowFactor
+	}
+	return 1
+}
+
+// ApplySpeed rescales a freshly scheduled card's interval by answer speed.
+func ApplySpeed(card *cards.Card, answer string, took time.Duration, now time.Time) {
+	if f := SpeedFactor(took, answer); f != 1 {
+		card.NextDue = now.Add(time.Duration(float64(card.NextDue.Sub(now)) * f))
+	}
+}
+
+// AlignDue moves day-scale due times to the start of their review day, so a
+// card learned late in the evening isn't held back until late tomorrow.
+func AlignDue(card *cards.Card, now time.Time) {
+	if card.NextDue.Sub(now) >= 24*time.Hour {
+		card.NextDue = DayStart(card.NextDue)
+	}
+}
+
+func reschedule(card *cards.Card, now time.Time) {
+	card.NextDue = now.Add(fuzzInterval(IntervalFor(*card)))
+}
+
+// Fuzz is review.fuzz and FuzzRand its source (review.fuzz_seed makes the
+// sequence repeatable).
+var (
+	Fuzz     float64
+	FuzzRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// fuzzInterval spreads intervals of a day or more by ±Fuzz, so cards learned
+// together drift apart instead of coming due in the same clump forever.
+func fuzzInterval(d time.Duration) time.Duration {
+	if Fuzz <= 0 || d < 24*time.Hour {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + Fuzz*(2*FuzzRand.Float64()-1)))
+}
+
+// TagSchedule adjusts the ladder for cards carrying a tag.
+type TagSchedule struct {
+	Intervals  []time.Duration // one per box, replaces the ladder
+	Multiplier float64         // scales the (possibly replaced) interval
+}
+
+// TagSchedules is review.tags.
+var TagSchedules map[string]TagSchedule
+
+// DangerFactor shortens danger cards' intervals when no tag schedule covers them.
+const DangerFactor = 0.5
+
+// IntervalFor is the card's box interval after per-tag adjustments; when
+// several configured tags apply, the tightest wins. Without configuration for
+// it, danger keeps its built-in halving.
+func IntervalFor(c cards.Card) time.Duration {
+	box := min(max(c.Box, 1), MaxBox())
+	base := Boxes[box]
+	interval, matched := base, false
+	for _, tag := range c.Tags {
+		s, ok := TagSchedules[tag]
+		if !ok {
+			continue
+		}
+		d := base
+		if len(s.Intervals) >= box {
+			d = s.Intervals[box-1]
+		}
+		if s.Multiplier > 0 {
+			d = time.Duration(float64(d) * s.Multiplier)
+		}
+		if !matched || d < interval {
+			interval, matched = d, true
+		}
+	}
+	if !matched && cards.HasTag(c, cards.DangerTag) {
+		interval = time.Duration(float64(base) * DangerFactor)
+	}
+	return interval
+}
+
+// DueCards is the due cards of deck, most valuable first (see Priority).
+func DueCards(deck []cards.Card, now time.Time) []cards.Card {
+	out := []cards.Card{}
+	for _, c := range deck {
+		if c.Due(now) {
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return Priority(out[i], now) > Priority(out[j], now) })
+	return out
+}
+
+// Priority ranks due cards so a partial session covers the most valuable ones:
+// how overdue (relative to the box interval), how recently the command was used,
+// how shaky the card is, and how often the command shows up at all.
+func Priority(c cards.Card, now time.Time) float64 {
+	interval := IntervalFor(c)
+	if interval < 24*time.Hour {
+		interval = 24 * time.Hour
+	}
+	overdue := math.Min(float64(now.Sub(c.NextDue))/float64(interval), 3)
+	if overdue < 0 {
+		overdue = 0
+	}
+
+	recency := 0.0
+	for _, o := range c.Origins {
+		if !o.LastSeen.IsZero() {
+			days := now.Sub(o.LastSeen).Hours() / 24
+			recency = math.Max(recency, math.Exp(-days/30))
+		}
+	}
+
+	difficulty := math.Max(float64(MaxBox()-c.Box)/float64(MaxBox()-1), 0) // 1 in box 1, 0 in the last box
+	if c.TimesSeen > 0 && c.Streak == 0 {
+		difficulty += 0.5 // failed last time
+	}
+
+	return overdue + recency + 0.8*difficulty + 0.3*math.Log1p(float64(c.SeenCount))
+}
+
+// IsNew is true for a card that was never answered.
+func IsNew(c cards.Card) bool { return c.TimesSeen == 0 }
+
+// MixNew caps the never-answered cards of a due queue at limit (< 0 = no cap)
+// and places them first, last, or spread evenly among the reviews ("mixed").
+func MixNew(queue []cards.Card, limit int, position string) []cards.Card {
+	fresh, reviews := []cards.Card{}, []cards.Card{}
+	for _, c := range queue {
+		switch {
+		case !IsNew(c):
+			reviews = append(reviews, c)
+		case limit < 0 || len(fresh) < limit:
+			fresh = append(fresh, c)
+		}
+	}
+	switch position {
+	case "first":
+		return append(fresh, reviews...)
+	case "last":
+		return append(reviews, fresh...)
+	}
+	out := make([]cards.Card, 0, len(fresh)+len(reviews))
+	for i, j := 0, 0; i < len(fresh) || j < len(reviews); {
+		// take whichever stream is further behind its share of the session
+		if j >= len(reviews) || i < len(fresh) && i*len(reviews) <= j*len(fresh) {
+			out = append(out, fresh[i])
+			i++
+		} else {
+			out = append(out, reviews[j])
+			j++
+		}
+	}
+	return out
+}
+
+// PinnedFirst puts every pinned, unsuspended card of deck at the front of
+// queue, due or not.
+func PinnedFirst(deck, queue []cards.Card) []cards.Card {
+	out := []cards.Card{}
+	for _, c := range deck {
+		if c.Pinned && !c.Suspended {
+			out = append(out, c)
+		}
+	}
+	for _, c := range queue {
+		if !c.Pinned {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// LimitNew keeps at most n never-reviewed cards carrying tag (n < 0 = no limit).
+func LimitNew(deck []cards.Card, tag string, n int) []cards.Card {
+	if n < 0 {
+		return deck
+	}
+	out := []cards.Card{}
+	for _, c := range deck {
+		if c.TimesSeen == 0 && cards.HasTag(c, tag) {
+			if n == 0 {
+				continue
+			}
+			n--
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// Review grades c for one answer at now with the card's scheduler. hard is a
+// correct answer that needed help (a hint, partial credit); took is how long
+// it took (0 = untimed) to type answer.
+func Review(c *cards.Card, answer string, correct, hard bool, took time.Duration, now time.Time) {
+	switch {
+	case SchedulerFor(*c) == "fsrs":
+		r := RateGood
+		if !correct {
+			r = RateAgain
+		} else if hard {
+			r = RateHard
+		} else if SpeedFactor(took, answer) > 1 {
+			r = RateEasy
+		}
+		FSRSGrade(c, r, now)
+	case correct && hard:
+		GradeHard(c, now)
+	default:
+		Grade(c, correct, now)
+		if correct {
+			ApplySpeed(c, answer, took, now)
+		}
+	}
+	AlignDue(c, now)
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"memento/pkg/cards"
+)
+
+// Storage holds the deck and the review log. Lock serializes writers (across
+// processes for the file backends); call the returned func to release it.
+type Storage interface {
+	Load() ([]cards.Card, error)
+	Save(deck []cards.Card) error
+	LoadReviews() ([]ReviewEntry, error)
+	AppendReview(e ReviewEntry) error
+	SaveReviews(all []ReviewEntry) error // replaces the whole log
+	Lock() (unlock func(), err error)
+	Stamp() (string, error) // changes whenever the deck is saved, by any process
+}
+
+// Backends lists the names Open accepts.
+var Backends = []string{"json", "sqlite"}
+
+// Open returns the named backend; "" is json. path is the SQLite database
+// (default memento.db in DataDir) and is ignored by json. Memory is not
+// configurable: it is for tests and library users.
+func Open(backend, path string) (Storage, error) {
+	switch backend {
+	case "", "json":
+		return JSON{}, nil
+	case "sqlite":
+		if path == "" {
+			d, err := DataDir()
+			if err != nil {
+				return nil, err
+			}
+			path = filepath.Join(d, "memento.db")
+		}
+		return OpenSQLite(path)
+	}
+	return nil, fmt.Errorf("unknown backend %q (want json or sqlite)", backend)
+}
+
+// JSON is the default backend: cards.json and reviews.jsonl in DataDir.
+type JSON struct{}
+
+func (JSON) Load() ([]cards.Card, error)         { return LoadCards() }
+func (JSON) Save(deck []cards.Card) error        { return SaveCards(deck) }
+func (JSON) LoadReviews() ([]ReviewEntry, error) { return LoadReviews() }
+func (JSON) AppendReview(e ReviewEntry) error    { return AppendReview(e) }
+func (JSON) SaveReviews(all []ReviewEntry) error { return SaveReviews(all) }
+
+// Stamp is the modification time and size of cards.json ("" before the first save).
+func (JSON) Stamp() (string, error) {
+	p, err := CardsPath()
+	if err != nil {
+		return "", err
+	}
+	fi, err := os.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", fi.ModTime().UnixNano(), fi.Size()), nil
+}
+
+// Lock takes the lock file in DataDir.
+func (JSON) Lock() (func(), error) {
+	d, err := DataDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		return nil, err
+	}
+	return lockFile(filepath.Join(d, "lock"))
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// backends opens a fresh, empty instance of every backend.
+func backends(t *testing.T) map[string]Storage {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "memento.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return map[string]Storage{"json": JSON{}, "sqlite": db, "memory": NewMemory(nil)}
+}
+
+func sameJSON(t *testing.T, got, want any) {
+	t.Helper()
+	g, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(g) != string(w) {
+		t.Errorf("got  %s\nwant %s", g, w)
+	}
+}
+
+func testDeck() []cards.Card {
+	at := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	return []cards.Card{
+		{
+			ID: "b2", Type: cards.Cloze, Prompt: "tar _____ archive.tgz", Answer: "-xzf", AltAnswers: []string{"xzf"},
+			Command: "tar -xzf archive.tgz", Tags: []string{"tar", "archive"}, Box: 3, NextDue: at.Add(72 * time.Hour),
+			LastReviewed: at, Streak: 2, TimesSeen: 4, SeenCount: 9, Notes: "x = extract",
+			Origins: []cards.Origin{{Host: "laptop", Shell: "zsh", File: "~/.zsh_history", FirstSeen: at.Add(-time.Hour), LastSeen: at}},
+			Pinned:  true, Created: at.Add(-time.Hour), Stability: 3.7, Difficulty: 5.2,
+		},
+		{ID: "a1", Prompt: "list all", Answer: "ls -la", Command: "ls -la", Tags: []string{}, Box: 1, Suspended: true, Created: at},
+		{ID: "c3", Prompt: "status", Answer: "git status", Command: "git status", Tags: []string{"git"}, Box: 5, Choices: []string{"a", "b"}, Created: at},
+	}
+}
+
+func testReviews() []ReviewEntry {
+	at := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	return []ReviewEntry{
+		{CardID: "b2", At: at, Correct: true, BoxBefore: 2, BoxAfter: 3, Answer: "-xzf", TookMS: 3100},
+		{CardID: "a1", At: at.Add(time.Minute), Hinted: true, Score: 0.5, BoxBefore: 1, BoxAfter: 1},
+	}
+}
+
+func TestBackendRoundTrip(t *testing.T) {
+	for name, st := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			deck, err := st.Load()
+			if err != nil || len(deck) != 0 {
+				t.Fatalf("empty Load = %v, %v", deck, err)
+			}
+			if rs, err := st.LoadReviews(); err != nil || len(rs) != 0 {
+				t.Fatalf("empty LoadReviews = %v, %v", rs, err)
+			}
+			before, err := st.Stamp()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			unlock, err := st.Lock()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := st.Save(testDeck()); err != nil {
+				t.Fatal(err)
+			}
+			unlock()
+			deck, err = st.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameJSON(t, deck, testDeck())
+			if after, err := st.Stamp(); err != nil || after == before {
+				t.Errorf("Stamp didn't change on Save: %q → %q (%v)", before, after, err)
+			}
+
+			if err := st.Save(testDeck()[1:]); err != nil {
+				t.Fatal(err)
+			}
+			deck, err = st.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameJSON(t, deck, testDeck()[1:])
+
+			for _, e := range testReviews() {
+				if err := st.AppendReview(e); err != nil {
+					t.Fatal(err)
+				}
+			}
+			rs, err := st.LoadReviews()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameJSON(t, rs, testReviews())
+			if err := st.SaveReviews(testReviews()[1:]); err != nil {
+				t.Fatal(err)
+			}
+			rs, err = st.LoadReviews()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameJSON(t, rs, testReviews()[1:])
+
+			unlock, err = st.Lock() // released above, so this doesn't block
+			if err != nil {
+				t.Fatal(err)
+			}
+			unlock()
+		})
+	}
+}
+
+func TestBackendStampsCreated(t *testing.T) {
+	for name, st := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := st.Save([]cards.Card{{ID: "x", Command: "ls"}}); err != nil {
+				t.Fatal(err)
+			}
+			deck, err := st.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(deck) != 1 || deck[0].Created.IsZero() {
+				t.Errorf("Created not set on save: %+v", deck)
+			}
+		})
+	}
+}
+
+func TestSQLiteDuplicateIDs(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "memento.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Save(testDeck()); err != nil {
+		t.Fatal(err)
+	}
+	dup := append(testDeck(), cards.Card{ID: "a1", Command: "ls"})
+	if err := db.Save(dup); err == nil {
+		t.Fatal("saving two cards with one ID succeeded")
+	}
+	deck, err := db.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameJSON(t, deck, testDeck())
+}
+
+func TestOpen(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	for _, b := range append([]string{""}, Backends...) {
+		st, err := Open(b, "")
+		if err != nil {
+			t.Errorf("Open(%q): %v", b, err)
+			continue
+		}
+		if db, ok := st.(*SQLite); ok {
+			db.Close()
+		}
+	}
+	if _, err := Open("memory", ""); err == nil {
+		t.Error(`Open("memory") succeeded; memory is not a configurable backend`)
+	}
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// JournalEntry is one card mutation in journal.jsonl: "put" carries the whole
+// card after the change, "del" just the ID. By names the command that made it
+// (review, ingest, tag, …); compact writes "base" entries.
+type JournalEntry struct {
+	At   time.Time   `json:"at"`
+	Op   string      `json:"op"`
+	By   string      `json:"by,omitempty"`
+	ID   string      `json:"id"`
+	Card *cards.Card `json:"card,omitempty"`
+}
+
+// JournalBy names the program or command making changes, e.g. "review".
+var JournalBy string
+
+// JournalPath is journal.jsonl next to cards.json.
+func JournalPath() (string, error) {
+	p, err := CardsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "journal.jsonl"), nil
+}
+
+// journalChanges appends what changed between the cards on disk (old) and
+// the ones about to be saved, and syncs it before cards.json is replaced.
+func journalChanges(old, deck []cards.Card, now time.Time) error {
+	jp, err := JournalPath()
+	if err != nil {
+		return err
+	}
+	before := map[string][]byte{}
+	if _, err := os.Stat(jp); err == nil { // no journal yet: every card goes in as the baseline
+		for _, c := range old {
+			before[c.ID], _ = json.Marshal(c)
+		}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	live := map[string]bool{}
+	for i := range deck {
+		c := &deck[i]
+		live[c.ID] = true
+		b, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		if prev, ok := before[c.ID]; ok && bytes.Equal(prev, b) {
+			continue
+		}
+		if err := enc.Encode(JournalEntry{At: now, Op: "put", By: JournalBy, ID: c.ID, Card: c}); err != nil {
+			return err
+		}
+	}
+	for id := range before {
+		if !live[id] {
+			if err := enc.Encode(JournalEntry{At: now, Op: "del", By: JournalBy, ID: id}); err != nil {
+				return err
+			}
+		}
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(jp, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReplayJournal folds the journal into the deck it describes, in the order
+// cards were first put. A torn last line (crash mid-append) is ignored.
+func ReplayJournal() (deck []cards.Card, entries int, err error) {
+	jp, err := JournalPath()
+	if err != nil {
+		return nil, 0, err
+	}
+	f, err := os.Open(jp)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, fmt.Errorf("no journal yet (%s); it starts with the next change", TildePath(jp))
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	idx := map[string]int{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		var e JournalEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			Logger.Warn("journal: skipping bad line", "line", line, "err", err)
+			continue
+		}
+		entries++
+		switch e.Op {
+		case "put", "base":
+			if e.Card == nil {
+				continue
+			}
+			if i, ok := idx[e.ID]; ok {
+				deck[i] = *e.Card
+			} else {
+				idx[e.ID] = len(deck)
+				deck = append(deck, *e.Card)
+			}
+		case "del":
+			if i, ok := idx[e.ID]; ok {
+				deck[i].ID = "" // dropped below, keeps indexes stable
+				delete(idx, e.ID)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, entries, err
+	}
+	live := []cards.Card{}
+	for _, c := range deck {
+		if c.ID != "" {
+			live = append(live, c)
+		}
+	}
+	return live, entries, nil
+}
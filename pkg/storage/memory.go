@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// Memory keeps everything in process, for tests and tools that must not
+// touch the data dir.
+type Memory struct {
+	mu      sync.Mutex // held by Lock
+	data    sync.Mutex // guards deck and reviews
+	deck    []cards.Card
+	reviews []ReviewEntry
+	saves   int
+}
+
+// NewMemory returns a Memory backend holding a copy of deck.
+func NewMemory(deck []cards.Card) *Memory {
+	return &Memory{deck: slices.Clone(deck)}
+}
+
+func (m *Memory) Load() ([]cards.Card, error) {
+	m.data.Lock()
+	defer m.data.Unlock()
+	if m.deck == nil {
+		return []cards.Card{}, nil
+	}
+	return slices.Clone(m.deck), nil
+}
+
+func (m *Memory) Save(deck []cards.Card) error {
+	m.data.Lock()
+	defer m.data.Unlock()
+	stampCreated(deck, time.Now())
+	m.deck = slices.Clone(deck)
+	m.saves++
+	return nil
+}
+
+func (m *Memory) LoadReviews() ([]ReviewEntry, error) {
+	m.data.Lock()
+	defer m.data.Unlock()
+	return append([]ReviewEntry{}, m.reviews...), nil
+}
+
+func (m *Memory) AppendReview(e ReviewEntry) error {
+	m.data.Lock()
+	defer m.data.Unlock()
+	m.reviews = append(m.reviews, e)
+	return nil
+}
+
+func (m *Memory) SaveReviews(all []ReviewEntry) error {
+	m.data.Lock()
+	defer m.data.Unlock()
+	m.reviews = slices.Clone(all)
+	return nil
+}
+
+func (m *Memory) Lock() (func(), error) {
+	m.mu.Lock()
+	return m.mu.Unlock, nil
+}
+
+func (m *Memory) Stamp() (string, error) {
+	m.data.Lock()
+	defer m.data.Unlock()
+	return strconv.Itoa(m.saves), nil
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ReviewEntry is one graded answer, appended to reviews.jsonl next to cards.json.
+type ReviewEntry struct {
+	CardID    string    `json:"card_id"`
+	At        time.Time `json:"at"`
+	Correct   bool      `json:"correct"`
+	Hinted    bool      `json:"hinted,omitempty"` // hint revealed before answering
+	Score     float64   `json:"score,omitempty"`  // share of parts right on a partly right multi-part answer
+	BoxBefore int       `json:"box_before"`
+	BoxAfter  int       `json:"box_after"`
+	Answer    string    `json:"answer,omitempty"`
+	TookMS    int64     `json:"took_ms,omitempty"` // card shown → answer submitted (TUI only)
+}
+
+// ReviewsPath is reviews.jsonl next to cards.json.
+func ReviewsPath() (string, error) {
+	p, err := CardsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "reviews.jsonl"), nil
+}
+
+// AppendReview adds e to the review log.
+func AppendReview(e ReviewEntry) error {
+	p, err := ReviewsPath()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadReviews reads the whole review log, skipping lines that don't parse.
+func LoadReviews() ([]ReviewEntry, error) {
+	p, err := ReviewsPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return []ReviewEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	out := []ReviewEntry{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var e ReviewEntry
+		if json.Unmarshal(s.Bytes(), &e) == nil {
+			out = append(out, e)
+		}
+	}
+	return out, s.Err()
+}
+
+// SaveReviews replaces the review log with all.
+func SaveReviews(all []ReviewEntry) error {
+	p, err := ReviewsPath()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range all {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return WriteFileAtomic(p, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// keepSnapshots is how many previous cards.json versions stay in snapshots/.
+const keepSnapshots = 5
+
+// WriteFileAtomic writes b next to p, syncs it and renames it over p, so a
+// crash leaves either the old file or the new one, never half of either.
+func WriteFileAtomic(p string, b []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op once renamed
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return err
+	}
+	if d, err := os.Open(filepath.Dir(p)); err == nil {
+		d.Sync() // persist the rename; not supported everywhere
+		d.Close()
+	}
+	return nil
+}
+
+// Checksum is the hex SHA-256 of b.
+func Checksum(b []byte) string { s := sha256.Sum256(b); return hex.EncodeToString(s[:]) }
+
+// SnapshotDir holds the previous versions of cards.json.
+func SnapshotDir() (string, error) {
+	d, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "snapshots"), nil
+}
+
+// Snapshot keeps the current cards.json (if it verifies) before it is replaced,
+// and drops all but the newest keepSnapshots.
+func Snapshot(p string, now time.Time) error {
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if sum, err := os.ReadFile(p + ".sha256"); err != nil || strings.TrimSpace(string(sum)) != Checksum(b) {
+		if _, err := verifyCards(p, b); err != nil {
+			return nil // never snapshot a damaged file
+		}
+	}
+	d, err := SnapshotDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		return err
+	}
+	dst := filepath.Join(d, "cards-"+now.UTC().Format("20060102T150405.000000000")+".json")
+	if err := os.Link(p, dst); err != nil { // cheap: the old inode survives the rename
+		if err := os.WriteFile(dst, b, 0o644); err != nil {
+			return err
+		}
+	}
+	snaps, err := Snapshots()
+	if err != nil {
+		return err
+	}
+	for _, s := range snaps[min(keepSnapshots, len(snaps)):] {
+		os.Remove(s)
+	}
+	return nil
+}
+
+// Snapshots lists snapshot files, newest first.
+func Snapshots() ([]string, error) {
+	d, err := SnapshotDir()
+	if err != nil {
+		return nil, err
+	}
+	out, err := filepath.Glob(filepath.Join(d, "cards-*.json"))
+	sort.Sort(sort.Reverse(sort.StringSlice(out)))
+	return out, err
+}
+
+// verifyCards checks b against the checksum written with it. A mismatch on
+// JSON that still parses is a hand edit, not damage.
+func verifyCards(p string, b []byte) ([]cards.Card, error) {
+	var deck []cards.Card
+	jerr := json.Unmarshal(b, &deck)
+	sum, err := os.ReadFile(p + ".sha256")
+	if err == nil && strings.TrimSpace(string(sum)) != Checksum(b) {
+		if jerr != nil {
+			return nil, fmt.Errorf("checksum mismatch (truncated or corrupt write): %w", jerr)
+		}
+		Logger.Warn("checksum mismatch on valid JSON, assuming a hand edit", "file", p)
+	}
+	return deck, jerr
+}
+
+// recoverCards restores the newest snapshot that parses after cards.json
+// failed to verify, moving the damaged file aside.
+func recoverCards(p string, damaged []byte, cause error) ([]cards.Card, error) {
+	snaps, err := Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range snaps {
+		b, err := os.ReadFile(s)
+		if err != nil {
+			continue
+		}
+		var deck []cards.Card
+		if json.Unmarshal(b, &deck) != nil {
+			continue
+		}
+		aside := p + ".corrupt-" + time.Now().Format("20060102-150405")
+		if err := os.WriteFile(aside, damaged, 0o644); err != nil {
+			return nil, err
+		}
+		if err := WriteCards(p, b); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "memento: %s is damaged (%v).\n  Restored %d cards from snapshot %s.\n  The damaged file was kept as %s.\n",
+			TildePath(p), cause, len(deck), TildePath(s), TildePath(aside))
+		Logger.Warn("recovered cards", "file", p, "snapshot", s, "cards", len(deck), "err", cause)
+		return deck, nil
+	}
+	d, _ := SnapshotDir()
+	return nil, fmt.Errorf("%s is damaged (%w) and %s has no usable snapshot; see memento rebuild and memento validate", p, cause, TildePath(d))
+}
+
+// WriteCards replaces cards.json and its checksum.
+func WriteCards(p string, b []byte) error {
+	if err := WriteFileAtomic(p, b, 0o644); err != nil {
+		return err
+	}
+	return WriteFileAtomic(p+".sha256", []byte(Checksum(b)+"\n"), 0o644)
+}
+
+// TildePath shortens paths under $HOME to ~/…
+func TildePath(p string) string {
+	if h, err := os.UserHomeDir(); err == nil && h != "" && strings.HasPrefix(p, h) {
+		return "~" + strings.TrimPrefix(p, h)
+	}
+	return p
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"memento/pkg/cards"
+)
+
+// SQLite keeps cards and reviews in one database file, each row holding the
+// JSON of a card or review entry.
+type SQLite struct {
+	db   *sql.DB
+	path string
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS cards (pos INTEGER NOT NULL, id TEXT PRIMARY KEY, data TEXT NOT NULL);
+CREATE TABLE IF NOT EXISTS reviews (seq INTEGER PRIMARY KEY AUTOINCREMENT, card_id TEXT NOT NULL, at TEXT NOT NULL, data TEXT NOT NULL);
+CREATE INDEX IF NOT EXISTS reviews_card ON reviews (card_id);
+CREATE TABLE IF NOT EXISTS meta (k TEXT PRIMARY KEY, v INTEGER NOT NULL);
+`
+
+// OpenSQLite opens (creating if needed) the database at path.
+func OpenSQLite(path string) (*SQLite, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLite{db: db, path: path}, nil
+}
+
+func (s *SQLite) Close() error { return s.db.Close() }
+
+func (s *SQLite) Load() ([]cards.Card, error) {
+	rows, err := s.db.Query(`SELECT data FROM cards ORDER BY pos`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deck := []cards.Card{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var c cards.Card
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return nil, err
+		}
+		deck = append(deck, c)
+	}
+	return deck, rows.Err()
+}
+
+// Save replaces every card in one transaction. A deck with two cards of the
+// same ID is refused rather than saved with one of them dropped.
+func (s *SQLite) Save(deck []cards.Card) error {
+	stampCreated(deck, time.Now())
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM cards`); err != nil {
+		return err
+	}
+	ins, err := tx.Prepare(`INSERT INTO cards (pos, id, data) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer ins.Close()
+	seen := map[string]bool{}
+	for i, c := range deck {
+		if seen[c.ID] {
+			return fmt.Errorf("%s: duplicate card ID %s", s.path, c.ID)
+		}
+		seen[c.ID] = true
+		b, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		if _, err := ins.Exec(i, c.ID, string(b)); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO meta (k, v) VALUES ('saves', 1) ON CONFLICT (k) DO UPDATE SET v = v + 1`); err != nil {
+		return err
+	}
+	Logger.Info("write", "file", s.path, "cards", len(deck))
+	return tx.Commit()
+}
+
+func (s *SQLite) LoadReviews() ([]ReviewEntry, error) {
+	rows, err := s.db.Query(`SELECT data FROM reviews ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []ReviewEntry{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var e ReviewEntry
+		if json.Unmarshal([]byte(data), &e) == nil {
+			out = append(out, e)
+		}
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLite) AppendReview(e ReviewEntry) error {
+	return insertReview(s.db, e)
+}
+
+func (s *SQLite) SaveReviews(all []ReviewEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM reviews`); err != nil {
+		return err
+	}
+	for _, e := range all {
+		if err := insertReview(tx, e); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertReview(db interface {
+	Exec(string, ...any) (sql.Result, error)
+}, e ReviewEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO reviews (card_id, at, data) VALUES (?, ?, ?)`, e.CardID, e.At.UTC().Format(time.RFC3339Nano), string(b))
+	return err
+}
+
+// Stamp counts saves in the meta table.
+func (s *SQLite) Stamp() (string, error) {
+	var n int64
+	err := s.db.QueryRow(`SELECT v FROM meta WHERE k = 'saves'`).Scan(&n)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "0", nil
+	}
+	return strconv.FormatInt(n, 10), err
+}
+
+// Lock takes a lock file next to the database.
+func (s *SQLite) Lock() (func(), error) { return lockFile(s.path + ".lock") }
@@ -0,0 +1,105 @@
+// Package storage keeps memento's data in the XDG data dir: cards.json
+// (checksummed, snapshotted before each write, every change journaled to
+// journal.jsonl) and the review log, reviews.jsonl. The Storage interface
+// puts the same deck and log behind other backends (SQLite, memory).
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"memento/pkg/cards"
+)
+
+// SchemaVersion is the layout of cards.json, the journal and the review log.
+// Bump it with a migration whenever a change needs one.
+const SchemaVersion = 1
+
+// Logger receives writes, recoveries and journal problems; it discards until set.
+var Logger = slog.New(slog.DiscardHandler)
+
+// DataDir is $XDG_DATA_HOME/memento (~/.local/share/memento by default).
+func DataDir() (string, error) {
+	if d := os.Getenv("XDG_DATA_HOME"); d != "" {
+		return filepath.Join(d, "memento"), nil
+	}
+	h, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(h, ".local", "share", "memento"), nil
+}
+
+// CardsPath is cards.json in DataDir, creating the directory.
+func CardsPath() (string, error) {
+	d, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "cards.json"), nil
+}
+
+// LoadCards reads cards.json, restoring the newest good snapshot when it
+// fails to verify. No file yet is an empty deck.
+func LoadCards() ([]cards.Card, error) {
+	p, err := CardsPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return []cards.Card{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	deck, err := verifyCards(p, b)
+	if err != nil {
+		return recoverCards(p, b, err)
+	}
+	return deck, nil
+}
+
+// SaveCards journals what changed, snapshots the old cards.json and replaces
+// it. Cards without a creation time get their first-seen time.
+func SaveCards(deck []cards.Card) error {
+	p, err := CardsPath()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	stampCreated(deck, now)
+	b, err := json.MarshalIndent(deck, "", " ")
+	if err != nil {
+		return err
+	}
+	Logger.Info("write", "file", p, "cards", len(deck), "bytes", len(b))
+	var old []cards.Card
+	if prev, err := os.ReadFile(p); err == nil {
+		_ = json.Unmarshal(prev, &old)
+	}
+	if err := journalChanges(old, deck, now); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	if err := Snapshot(p, now); err != nil {
+		return err
+	}
+	return WriteCards(p, b)
+}
+
+// stampCreated gives cards without a creation time their first-seen time.
+func stampCreated(deck []cards.Card, now time.Time) {
+	for i := range deck {
+		if deck[i].Created.IsZero() {
+			deck[i].Created = deck[i].FirstSeen(now)
+		}
+	}
+}